@@ -7,11 +7,17 @@ import (
 	"github.com/apex/go-apex"
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/json"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
 
 	"github.com/apex/up"
 	"github.com/apex/up/handler"
 	"github.com/apex/up/internal/logs"
 	"github.com/apex/up/internal/proxy"
+	"github.com/apex/up/internal/record"
+	"github.com/apex/up/internal/remote"
+	"github.com/apex/up/internal/router"
 	"github.com/apex/up/internal/util"
 	"github.com/apex/up/platform/aws/runtime"
 )
@@ -65,7 +71,26 @@ func main() {
 		ctx.Fatalf("error initializing handler: %s", err)
 	}
 
+	// proxy handler
+	ph := proxy.NewHandler(h)
+
+	// request recording
+	if c.Proxy.Record.Enable {
+		ph, err = record.NewHandler(&c.Proxy.Record, stage, ph)
+		if err != nil {
+			ctx.Fatalf("error initializing recorder: %s", err)
+		}
+	}
+
+	// commit routing
+	client := lambda.New(session.New(aws.NewConfig()))
+	ph = router.New(c.Name, os.Getenv("UP_COMMIT"), client, ph)
+
+	// remote commands
+	timeout := time.Duration(c.Proxy.Timeout) * time.Second
+	ph = remote.NewHandler(os.Getenv(remote.SecretEnv), timeout, ph)
+
 	// serve
 	log.WithField("duration", util.MillisecondsSince(start)).Info("initialized")
-	apex.Handle(proxy.NewHandler(h))
+	apex.Handle(ph)
 }
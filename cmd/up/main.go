@@ -15,8 +15,10 @@ import (
 	_ "github.com/apex/up/internal/cli/deploy"
 	_ "github.com/apex/up/internal/cli/docs"
 	_ "github.com/apex/up/internal/cli/domains"
+	_ "github.com/apex/up/internal/cli/invoke"
 	_ "github.com/apex/up/internal/cli/logs"
 	_ "github.com/apex/up/internal/cli/metrics"
+	_ "github.com/apex/up/internal/cli/replay"
 	_ "github.com/apex/up/internal/cli/run"
 	_ "github.com/apex/up/internal/cli/stack"
 	_ "github.com/apex/up/internal/cli/start"
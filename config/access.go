@@ -0,0 +1,101 @@
+package config
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Access config.
+type Access struct {
+	// TrustedProxies is a list of proxy addresses or CIDR ranges
+	// whose X-Forwarded-For header field is honored.
+	TrustedProxies []string `json:"trusted_proxies"`
+
+	// Rules is a list of access rules, all
+	// matching rules must allow the request.
+	Rules []*AccessRule `json:"rules"`
+}
+
+// AccessRule is a set of allowed or denied addresses for matching paths.
+type AccessRule struct {
+	// Path pattern. Defaults to "/*".
+	Path string `json:"path"`
+
+	// Allow is a list of addresses or CIDR ranges allowed, when
+	// present all other addresses are denied.
+	Allow []string `json:"allow"`
+
+	// Deny is a list of addresses or CIDR ranges denied.
+	Deny []string `json:"deny"`
+
+	// Status code of blocked responses. Defaults to 403.
+	Status int `json:"status"`
+}
+
+// Default implementation.
+func (a *Access) Default() error {
+	for _, r := range a.Rules {
+		if r.Path == "" {
+			r.Path = "/*"
+		}
+
+		if r.Status == 0 {
+			r.Status = http.StatusForbidden
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (a *Access) Validate() error {
+	if err := validateCIDRs(a.TrustedProxies); err != nil {
+		return errors.Wrap(err, ".trusted_proxies")
+	}
+
+	for i, r := range a.Rules {
+		if err := r.Validate(); err != nil {
+			return errors.Wrapf(err, ".rules[%d]", i)
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (r *AccessRule) Validate() error {
+	if len(r.Allow) == 0 && len(r.Deny) == 0 {
+		return errors.New(".allow or .deny is required")
+	}
+
+	if err := validateCIDRs(r.Allow); err != nil {
+		return errors.Wrap(err, ".allow")
+	}
+
+	if err := validateCIDRs(r.Deny); err != nil {
+		return errors.Wrap(err, ".deny")
+	}
+
+	if r.Status < 400 || r.Status > 599 {
+		return errors.Errorf(".status %d is invalid, must be an error status", r.Status)
+	}
+
+	return nil
+}
+
+// validateCIDRs returns an error if an address or CIDR range is invalid.
+func validateCIDRs(list []string) error {
+	for _, s := range list {
+		if net.ParseIP(s) != nil {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return errors.Errorf("%q is not a valid address or CIDR range", s)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestAccess(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Access{Rules: []*AccessRule{{Allow: []string{"10.0.0.0/8"}}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, "/*", c.Rules[0].Path)
+		assert.Equal(t, 403, c.Rules[0].Status)
+	})
+
+	t.Run("missing addresses", func(t *testing.T) {
+		c := &Access{Rules: []*AccessRule{{Path: "/admin"}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.rules[0]: .allow or .deny is required`)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		c := &Access{Rules: []*AccessRule{{Deny: []string{"10.0.0.0/99"}}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.rules[0]: .deny: "10.0.0.0/99" is not a valid address or CIDR range`)
+	})
+
+	t.Run("invalid trusted proxy", func(t *testing.T) {
+		c := &Access{TrustedProxies: []string{"proxy"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.trusted_proxies: "proxy" is not a valid address or CIDR range`)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		c := &Access{Rules: []*AccessRule{{Deny: []string{"::1"}, Status: 200}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.rules[0]: .status 200 is invalid, must be an error status`)
+	})
+}
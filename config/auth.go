@@ -0,0 +1,115 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// algorithms supported for JWT verification.
+var algorithms = []string{
+	"RS256",
+	"RS384",
+	"RS512",
+	"PS256",
+	"PS384",
+	"PS512",
+	"ES256",
+	"ES384",
+	"ES512",
+}
+
+// Auth config.
+type Auth struct {
+	// JWT bearer token validation.
+	JWT *JWT `json:"jwt"`
+}
+
+// Default implementation.
+func (a *Auth) Default() error {
+	if a.JWT != nil {
+		if err := a.JWT.Default(); err != nil {
+			return errors.Wrap(err, ".jwt")
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (a *Auth) Validate() error {
+	if a.JWT != nil {
+		if err := a.JWT.Validate(); err != nil {
+			return errors.Wrap(err, ".jwt")
+		}
+	}
+
+	return nil
+}
+
+// JWT config.
+type JWT struct {
+	// Issuer required in the "iss" claim, also used for
+	// OpenID Connect discovery when no JWKS is specified.
+	Issuer string `json:"issuer"`
+
+	// Audiences is a list of accepted "aud" claim values.
+	Audiences []string `json:"audiences"`
+
+	// Algorithms allowed for signatures. Defaults to RS256.
+	Algorithms []string `json:"algorithms"`
+
+	// JWKSURL is the url of the JSON Web Key Set.
+	JWKSURL string `json:"jwks_url"`
+
+	// JWKSFile is the path of a local JSON Web Key Set.
+	JWKSFile string `json:"jwks_file"`
+
+	// Rules map of path patterns to rules. Defaults
+	// to requiring a valid token for all paths.
+	Rules map[string]*JWTRule `json:"rules"`
+}
+
+// JWTRule is a set of requirements for matching paths.
+type JWTRule struct {
+	// Disable token validation for the path.
+	Disable bool `json:"disable"`
+
+	// Scopes required in the "scope" or "scp" claim.
+	Scopes []string `json:"scopes"`
+
+	// Claims required with the given values.
+	Claims map[string]string `json:"claims"`
+}
+
+// Default implementation.
+func (j *JWT) Default() error {
+	if len(j.Algorithms) == 0 {
+		j.Algorithms = []string{"RS256"}
+	}
+
+	if len(j.Rules) == 0 {
+		j.Rules = map[string]*JWTRule{
+			"/*": {},
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (j *JWT) Validate() error {
+	if j.JWKSURL == "" && j.JWKSFile == "" && j.Issuer == "" {
+		return errors.New(".jwks_url, .jwks_file or .issuer is required")
+	}
+
+	if j.JWKSURL != "" && j.JWKSFile != "" {
+		return errors.New(".jwks_url and .jwks_file are mutually exclusive")
+	}
+
+	if err := validate.Lists(j.Algorithms, algorithms); err != nil {
+		return errors.Wrap(err, ".algorithms")
+	}
+
+	return nil
+}
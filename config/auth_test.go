@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestAuth(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Auth{JWT: &JWT{Issuer: "https://example.com/"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, []string{"RS256"}, c.JWT.Algorithms)
+		assert.Equal(t, map[string]*JWTRule{"/*": {}}, c.JWT.Rules)
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		c := &Auth{JWT: &JWT{}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.jwt: .jwks_url, .jwks_file or .issuer is required`)
+	})
+
+	t.Run("conflicting keys", func(t *testing.T) {
+		c := &Auth{JWT: &JWT{JWKSURL: "https://example.com/keys", JWKSFile: "keys.json"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.jwt: .jwks_url and .jwks_file are mutually exclusive`)
+	})
+
+	t.Run("invalid algorithm", func(t *testing.T) {
+		c := &Auth{JWT: &JWT{Issuer: "https://example.com/", Algorithms: []string{"HS256"}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.Error(t, c.Validate())
+	})
+}
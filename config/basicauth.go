@@ -0,0 +1,74 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuth config.
+type BasicAuth struct {
+	// Disable basic auth, typically used to
+	// override it for a stage such as production.
+	Disable bool `json:"disable"`
+
+	// Realm presented to the client.
+	Realm string `json:"realm"`
+
+	// Users is a map of usernames to bcrypt password hashes.
+	Users map[string]string `json:"users"`
+
+	// Paths is a list of path patterns requiring
+	// authentication. Defaults to all paths.
+	Paths []string `json:"paths"`
+
+	// Exclude is a list of path patterns which
+	// do not require authentication, such as /health.
+	Exclude []string `json:"exclude"`
+}
+
+// Default implementation.
+func (b *BasicAuth) Default() error {
+	if b.Realm == "" {
+		b.Realm = "Restricted"
+	}
+
+	if len(b.Paths) == 0 {
+		b.Paths = []string{"/*"}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (b *BasicAuth) Validate() error {
+	for name, hash := range b.Users {
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return errors.Wrapf(err, ".users %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Override config.
+func (b *BasicAuth) Override(c *Config) {
+	if b.Disable {
+		c.BasicAuth.Disable = true
+	}
+
+	if b.Realm != "" {
+		c.BasicAuth.Realm = b.Realm
+	}
+
+	if b.Users != nil {
+		c.BasicAuth.Users = b.Users
+	}
+
+	if b.Paths != nil {
+		c.BasicAuth.Paths = b.Paths
+	}
+
+	if b.Exclude != nil {
+		c.BasicAuth.Exclude = b.Exclude
+	}
+}
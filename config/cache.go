@@ -0,0 +1,62 @@
+package config
+
+import (
+	humanize "github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+)
+
+// Cache config.
+type Cache struct {
+	// MaxSize of the cache per function instance, such as "50MB". Defaults to "25MB".
+	MaxSize string `json:"max_size"`
+
+	// Rules map of path patterns to rules.
+	Rules map[string]*CacheRule `json:"rules"`
+}
+
+// CacheRule is a set of caching options for matching paths.
+type CacheRule struct {
+	// Disable caching for the path.
+	Disable bool `json:"disable"`
+
+	// Vary is a list of request header fields included in the cache key.
+	Vary []string `json:"vary"`
+
+	// MaxAge of responses which do not specify a max-age or s-maxage.
+	MaxAge Duration `json:"max_age"`
+}
+
+// Default implementation.
+func (c *Cache) Default() error {
+	if c.MaxSize == "" {
+		c.MaxSize = "25MB"
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (c *Cache) Validate() error {
+	n, err := humanize.ParseBytes(c.MaxSize)
+	if err != nil {
+		return errors.Wrap(err, ".max_size")
+	}
+
+	if n == 0 {
+		return errors.New(".max_size must be greater than 0")
+	}
+
+	for path, r := range c.Rules {
+		if r.MaxAge < 0 {
+			return errors.Errorf(".rules %s: .max_age must be positive", path)
+		}
+	}
+
+	return nil
+}
+
+// Size returns the maximum size in bytes.
+func (c *Cache) Size() int64 {
+	n, _ := humanize.ParseBytes(c.MaxSize)
+	return int64(n)
+}
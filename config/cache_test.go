@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Cache{}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, int64(25000000), c.Size())
+	})
+
+	t.Run("size", func(t *testing.T) {
+		c := &Cache{MaxSize: "1MiB"}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, int64(1<<20), c.Size())
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		c := &Cache{MaxSize: "lots"}
+		assert.NoError(t, c.Default(), "default")
+		assert.Error(t, c.Validate())
+	})
+}
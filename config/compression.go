@@ -0,0 +1,83 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// encodings supported for compression.
+var encodings = []string{
+	"br",
+	"gzip",
+}
+
+// Compression config.
+type Compression struct {
+	// Disable compression.
+	Disable bool `json:"disable"`
+
+	// Algorithms in order of preference. Defaults to br and gzip.
+	Algorithms []string `json:"algorithms"`
+
+	// Level of compression, from 1 (fastest) to 9 (best).
+	// Defaults to each algorithm's default level.
+	Level int `json:"level"`
+
+	// MinSize is the minimum response size in bytes
+	// to be compressed. Defaults to 1400.
+	MinSize int `json:"min_size"`
+
+	// ContentTypes is a list of content types to compress, such as
+	// "text/*" or "application/json". Defaults to all types.
+	ContentTypes []string `json:"content_types"`
+
+	// ExcludeContentTypes is a list of content types which are
+	// not compressed. Defaults to already-compressed media types.
+	ExcludeContentTypes []string `json:"exclude_content_types"`
+}
+
+// Default implementation.
+func (c *Compression) Default() error {
+	if len(c.Algorithms) == 0 {
+		c.Algorithms = []string{"br", "gzip"}
+	}
+
+	if c.MinSize == 0 {
+		c.MinSize = 1400
+	}
+
+	if c.ExcludeContentTypes == nil {
+		c.ExcludeContentTypes = []string{
+			"image/png",
+			"image/jpeg",
+			"image/gif",
+			"image/webp",
+			"video/*",
+			"audio/*",
+			"font/woff",
+			"font/woff2",
+			"application/zip",
+			"application/gzip",
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (c *Compression) Validate() error {
+	if err := validate.Lists(c.Algorithms, encodings); err != nil {
+		return errors.Wrap(err, ".algorithms")
+	}
+
+	if c.Level < 0 || c.Level > 9 {
+		return errors.New(".level must be between 1 and 9")
+	}
+
+	if c.MinSize < 0 {
+		return errors.New(".min_size must be positive")
+	}
+
+	return nil
+}
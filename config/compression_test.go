@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCompression(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Compression{}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, []string{"br", "gzip"}, c.Algorithms)
+		assert.Equal(t, 1400, c.MinSize)
+		assert.Contains(t, c.ExcludeContentTypes, "image/png")
+	})
+
+	t.Run("invalid algorithm", func(t *testing.T) {
+		c := &Compression{Algorithms: []string{"deflate"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.Error(t, c.Validate())
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		c := &Compression{Level: 12}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.level must be between 1 and 9`)
+	})
+}
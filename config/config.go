@@ -10,6 +10,7 @@ import (
 
 	"github.com/apex/up/internal/header"
 	"github.com/apex/up/internal/inject"
+	"github.com/apex/up/internal/ratelimit"
 	"github.com/apex/up/internal/redirect"
 	"github.com/apex/up/internal/validate"
 	"github.com/apex/up/platform/aws/regions"
@@ -28,24 +29,32 @@ type validator interface {
 
 // Config for the project.
 type Config struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Type        string         `json:"type"`
-	Headers     header.Rules   `json:"headers"`
-	Redirects   redirect.Rules `json:"redirects"`
-	Hooks       Hooks          `json:"hooks"`
-	Environment Environment    `json:"environment"`
-	Regions     []string       `json:"regions"`
-	Profile     string         `json:"profile"`
-	Inject      inject.Rules   `json:"inject"`
-	Lambda      Lambda         `json:"lambda"`
-	CORS        *CORS          `json:"cors"`
-	ErrorPages  ErrorPages     `json:"error_pages"`
-	Proxy       Relay          `json:"proxy"`
-	Static      Static         `json:"static"`
-	Logs        Logs           `json:"logs"`
-	Stages      Stages         `json:"stages"`
-	DNS         DNS            `json:"dns"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Headers     header.Rules    `json:"headers"`
+	Redirects   redirect.Rules  `json:"redirects"`
+	Hooks       Hooks           `json:"hooks"`
+	Environment Environment     `json:"environment"`
+	Regions     []string        `json:"regions"`
+	Profile     string          `json:"profile"`
+	Inject      inject.Rules    `json:"inject"`
+	Lambda      Lambda          `json:"lambda"`
+	CORS        *CORS           `json:"cors"`
+	ErrorPages  ErrorPages      `json:"error_pages"`
+	Proxy       Relay           `json:"proxy"`
+	Static      Static          `json:"static"`
+	Logs        Logs            `json:"logs"`
+	Stages      Stages          `json:"stages"`
+	DNS         DNS             `json:"dns"`
+	Deploy      Deploy          `json:"deploy"`
+	RateLimit   ratelimit.Rules `json:"rate_limit"`
+	BasicAuth   BasicAuth       `json:"basic_auth"`
+	Auth        Auth            `json:"auth"`
+	Access      Access          `json:"access"`
+	Cache       Cache           `json:"cache"`
+	Compression Compression     `json:"compression"`
+	Security    Security        `json:"security"`
 }
 
 // Validate implementation.
@@ -74,10 +83,42 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".static")
 	}
 
+	if err := c.Redirects.Validate(); err != nil {
+		return errors.Wrap(err, ".redirects")
+	}
+
 	if err := c.Inject.Validate(); err != nil {
 		return errors.Wrap(err, ".inject")
 	}
 
+	if err := c.BasicAuth.Validate(); err != nil {
+		return errors.Wrap(err, ".basic_auth")
+	}
+
+	if err := c.Auth.Validate(); err != nil {
+		return errors.Wrap(err, ".auth")
+	}
+
+	if err := c.Access.Validate(); err != nil {
+		return errors.Wrap(err, ".access")
+	}
+
+	if err := c.RateLimit.Validate(); err != nil {
+		return errors.Wrap(err, ".rate_limit")
+	}
+
+	if err := c.Cache.Validate(); err != nil {
+		return errors.Wrap(err, ".cache")
+	}
+
+	if err := c.Compression.Validate(); err != nil {
+		return errors.Wrap(err, ".compression")
+	}
+
+	if err := c.Security.Validate(); err != nil {
+		return errors.Wrap(err, ".security")
+	}
+
 	if err := c.Lambda.Validate(); err != nil {
 		return errors.Wrap(err, ".lambda")
 	}
@@ -90,6 +131,10 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".stages")
 	}
 
+	if err := c.Deploy.Validate(); err != nil {
+		return errors.Wrap(err, ".deploy")
+	}
+
 	if len(c.Regions) > 1 {
 		return errors.New("multiple regions is not yet supported, see https://github.com/apex/up/issues/134")
 	}
@@ -163,16 +208,61 @@ func (c *Config) Default() error {
 		return errors.Wrap(err, ".inject")
 	}
 
+	// default .rate_limit
+	if err := c.RateLimit.Default(); err != nil {
+		return errors.Wrap(err, ".rate_limit")
+	}
+
+	// default .basic_auth
+	if err := c.BasicAuth.Default(); err != nil {
+		return errors.Wrap(err, ".basic_auth")
+	}
+
+	// default .auth
+	if err := c.Auth.Default(); err != nil {
+		return errors.Wrap(err, ".auth")
+	}
+
+	// default .access
+	if err := c.Access.Default(); err != nil {
+		return errors.Wrap(err, ".access")
+	}
+
+	// default .cache
+	if err := c.Cache.Default(); err != nil {
+		return errors.Wrap(err, ".cache")
+	}
+
+	// default .compression
+	if err := c.Compression.Default(); err != nil {
+		return errors.Wrap(err, ".compression")
+	}
+
+	// default .security
+	if err := c.Security.Default(); err != nil {
+		return errors.Wrap(err, ".security")
+	}
+
 	// default .static
 	if err := c.Static.Default(); err != nil {
 		return errors.Wrap(err, ".static")
 	}
 
+	// default error pages dir to static dir
+	if c.ErrorPages.Dir == "" {
+		c.ErrorPages.Dir = c.Static.Dir
+	}
+
 	// default .error_pages
 	if err := c.ErrorPages.Default(); err != nil {
 		return errors.Wrap(err, ".error_pages")
 	}
 
+	// default .deploy
+	if err := c.Deploy.Default(); err != nil {
+		return errors.Wrap(err, ".deploy")
+	}
+
 	// default .stages
 	if err := c.Stages.Default(); err != nil {
 		return errors.Wrap(err, ".stages")
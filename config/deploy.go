@@ -0,0 +1,97 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// Deployment strategies.
+const (
+	AllAtOnce               = "all_at_once"
+	Canary10Percent5Minutes = "canary10percent5minutes"
+	Linear                  = "linear"
+)
+
+// Deploy config.
+type Deploy struct {
+	// Strategy used to shift traffic to a new version.
+	Strategy string `json:"strategy"`
+
+	// Rollback thresholds applied while shifting traffic.
+	Rollback Rollback `json:"rollback"`
+}
+
+// Step is a traffic shifting step, routing Weight
+// of the traffic to the new version for Wait.
+type Step struct {
+	Weight float64
+	Wait   time.Duration
+}
+
+// Default implementation.
+func (d *Deploy) Default() error {
+	if d.Strategy == "" {
+		d.Strategy = AllAtOnce
+	}
+
+	if err := d.Rollback.Default(); err != nil {
+		return errors.Wrap(err, ".rollback")
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (d *Deploy) Validate() error {
+	strategies := []string{AllAtOnce, Canary10Percent5Minutes, Linear}
+
+	if err := validate.List(d.Strategy, strategies); err != nil {
+		return errors.Wrap(err, ".strategy")
+	}
+
+	return nil
+}
+
+// Steps returns the traffic shifting steps for the strategy,
+// excluding the final step routing all traffic to the new version.
+func (d *Deploy) Steps() (steps []Step) {
+	switch d.Strategy {
+	case Canary10Percent5Minutes:
+		steps = append(steps, Step{Weight: 0.1, Wait: 5 * time.Minute})
+	case Linear:
+		for i := 1; i < 10; i++ {
+			steps = append(steps, Step{Weight: float64(i) / 10, Wait: time.Minute})
+		}
+	}
+
+	return
+}
+
+// Rollback config.
+type Rollback struct {
+	// Errors is the number of function errors triggering a rollback.
+	Errors int `json:"errors"`
+
+	// Errors5xx is the number of 5xx responses triggering a rollback.
+	Errors5xx int `json:"errors_5xx"`
+
+	// Latency is the average latency in milliseconds triggering
+	// a rollback, or 0 to disable.
+	Latency int `json:"latency"`
+}
+
+// Default implementation.
+func (r *Rollback) Default() error {
+	if r.Errors == 0 {
+		r.Errors = 1
+	}
+
+	if r.Errors5xx == 0 {
+		r.Errors5xx = 1
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestDeploy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Deploy{}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, AllAtOnce, c.Strategy)
+		assert.Equal(t, 1, c.Rollback.Errors)
+		assert.Equal(t, 1, c.Rollback.Errors5xx)
+		assert.Equal(t, 0, c.Rollback.Latency)
+		assert.Empty(t, c.Steps())
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		c := &Deploy{Strategy: "sometimes"}
+		assert.NoError(t, c.Default(), "default")
+		assert.Error(t, c.Validate())
+	})
+
+	t.Run("canary", func(t *testing.T) {
+		c := &Deploy{Strategy: Canary10Percent5Minutes}
+		assert.NoError(t, c.Default(), "default")
+		assert.Equal(t, []Step{{Weight: 0.1, Wait: 5 * time.Minute}}, c.Steps())
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		c := &Deploy{Strategy: Linear}
+		assert.NoError(t, c.Default(), "default")
+		steps := c.Steps()
+		assert.Len(t, steps, 9)
+		assert.Equal(t, Step{Weight: 0.1, Wait: time.Minute}, steps[0])
+		assert.Equal(t, Step{Weight: 0.9, Wait: time.Minute}, steps[8])
+	})
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Recording config.
+type Recording struct {
+	// Enable request recording.
+	Enable bool `json:"enable"`
+
+	// Sample is the ratio of requests recorded,
+	// from 0 to 1. Defaults to 0.01 (1%).
+	Sample float64 `json:"sample"`
+
+	// Output is the destination of recorded events,
+	// either a file path or an s3://bucket/prefix url.
+	Output string `json:"output"`
+
+	// Redact is a list of header and query string
+	// parameter names which are redacted, in addition
+	// to the defaults such as Authorization and Cookie.
+	Redact []string `json:"redact"`
+}
+
+// Default implementation.
+func (r *Recording) Default() error {
+	if r.Sample == 0 {
+		r.Sample = 0.01
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (r *Recording) Validate() error {
+	if !r.Enable {
+		return nil
+	}
+
+	if r.Sample < 0 || r.Sample > 1 {
+		err := errors.New("should be between 0 and 1")
+		return errors.Wrap(err, ".sample")
+	}
+
+	if r.Output == "" {
+		err := errors.New("should not be empty")
+		return errors.Wrap(err, ".output")
+	}
+
+	return nil
+}
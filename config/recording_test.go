@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestRecording(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Recording{}
+		assert.NoError(t, c.Default(), "default")
+		assert.Equal(t, 0.01, c.Sample)
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("missing output", func(t *testing.T) {
+		c := &Recording{Enable: true}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.output: should not be empty`)
+	})
+
+	t.Run("invalid sample", func(t *testing.T) {
+		c := &Recording{Enable: true, Sample: 5, Output: "/tmp/events.json"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.sample: should be between 0 and 1`)
+	})
+}
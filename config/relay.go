@@ -28,6 +28,9 @@ type Relay struct {
 	// sending a SIGINT before sending a SIGKILL.
 	ShutdownTimeout int `json:"shutdown_timeout"`
 
+	// Record configuration for request recording.
+	Record Recording `json:"record"`
+
 	// platform is a currently unexported designation of the target deploy platform for this Relay
 	platform string
 }
@@ -54,6 +57,10 @@ func (r *Relay) Default() error {
 		return errors.Wrap(err, ".backoff")
 	}
 
+	if err := r.Record.Default(); err != nil {
+		return errors.Wrap(err, ".record")
+	}
+
 	if r.Retry != nil && !*r.Retry {
 		r.Backoff.Attempts = 0
 	}
@@ -97,6 +104,10 @@ func (r *Relay) Validate() error {
 		return errors.Wrap(err, ".shutdown_timeout")
 	}
 
+	if err := r.Record.Validate(); err != nil {
+		return errors.Wrap(err, ".record")
+	}
+
 	return nil
 }
 
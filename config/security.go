@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/csp"
+	"github.com/apex/up/internal/validate"
+)
+
+// preset of security headers.
+type preset struct {
+	headers map[string]string
+	policy  csp.Policy
+}
+
+// presets available.
+var presets = map[string]preset{
+	"basic": {
+		headers: map[string]string{
+			"Strict-Transport-Security": "max-age=31536000",
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "SAMEORIGIN",
+			"Referrer-Policy":           "strict-origin-when-cross-origin",
+		},
+	},
+
+	"strict": {
+		headers: map[string]string{
+			"Strict-Transport-Security":  "max-age=63072000; includeSubDomains; preload",
+			"X-Content-Type-Options":     "nosniff",
+			"X-Frame-Options":            "DENY",
+			"Referrer-Policy":            "no-referrer",
+			"Permissions-Policy":         "camera=(), geolocation=(), microphone=(), payment=(), usb=()",
+			"Cross-Origin-Opener-Policy": "same-origin",
+		},
+		policy: csp.Policy{
+			"default-src":     {"'self'"},
+			"script-src":      {"'self'", csp.Nonce},
+			"style-src":       {"'self'", csp.Nonce},
+			"img-src":         {"'self'", "data:"},
+			"object-src":      {"'none'"},
+			"base-uri":        {"'self'"},
+			"frame-ancestors": {"'none'"},
+			"form-action":     {"'self'"},
+		},
+	},
+}
+
+// Security config.
+type Security struct {
+	// Preset of security headers, "basic" or "strict".
+	Preset string `json:"preset"`
+
+	// Headers overriding those of the preset,
+	// an empty value removes the header field.
+	Headers map[string]string `json:"headers"`
+
+	// CSP is the Content-Security-Policy.
+	CSP CSP `json:"csp"`
+}
+
+// CSP config.
+type CSP struct {
+	// Directives overriding those of the preset. The "'nonce'"
+	// source is replaced with a nonce generated per request.
+	Directives csp.Policy `json:"directives"`
+
+	// ReportOnly sends the Content-Security-Policy-Report-Only
+	// header field, reporting violations without enforcing them.
+	ReportOnly bool `json:"report_only"`
+
+	// ReportURI is the url violations are reported to.
+	ReportURI string `json:"report_uri"`
+}
+
+// Default implementation.
+func (s *Security) Default() error {
+	p := presets[s.Preset]
+
+	headers := make(map[string]string)
+
+	for k, v := range p.headers {
+		headers[k] = v
+	}
+
+	for k, v := range s.Headers {
+		headers[k] = v
+	}
+
+	s.Headers = headers
+	s.CSP.Directives = p.policy.Merge(s.CSP.Directives)
+
+	if s.CSP.ReportURI != "" {
+		s.CSP.Directives["report-uri"] = []string{s.CSP.ReportURI}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (s *Security) Validate() error {
+	if s.Preset != "" {
+		if err := validate.List(s.Preset, []string{"basic", "strict"}); err != nil {
+			return errors.Wrap(err, ".preset")
+		}
+	}
+
+	if err := s.CSP.Directives.Validate(); err != nil {
+		return errors.Wrap(err, ".csp.directives")
+	}
+
+	if len(s.CSP.Directives) == 0 && (s.CSP.ReportOnly || s.CSP.ReportURI != "") {
+		return errors.New(".csp.directives are required for reporting")
+	}
+
+	return nil
+}
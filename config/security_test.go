@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up/internal/csp"
+)
+
+func TestSecurity(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Security{}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Empty(t, c.Headers)
+		assert.Empty(t, c.CSP.Directives)
+	})
+
+	t.Run("preset", func(t *testing.T) {
+		c := &Security{Preset: "basic"}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, "nosniff", c.Headers["X-Content-Type-Options"])
+		assert.Empty(t, c.CSP.Directives)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		c := &Security{
+			Preset: "strict",
+			Headers: map[string]string{
+				"X-Frame-Options": "SAMEORIGIN",
+				"Referrer-Policy": "",
+			},
+			CSP: CSP{
+				Directives: csp.Policy{
+					"img-src": {"*"},
+				},
+				ReportURI: "/csp",
+			},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, "SAMEORIGIN", c.Headers["X-Frame-Options"])
+		assert.Equal(t, "", c.Headers["Referrer-Policy"])
+		assert.Equal(t, []string{"*"}, c.CSP.Directives["img-src"])
+		assert.Equal(t, []string{"'self'", csp.Nonce}, c.CSP.Directives["script-src"])
+		assert.Equal(t, []string{"/csp"}, c.CSP.Directives["report-uri"])
+		assert.Equal(t, []string{"'self'", "data:"}, presets["strict"].policy["img-src"])
+	})
+
+	t.Run("invalid preset", func(t *testing.T) {
+		c := &Security{Preset: "paranoid"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.preset: "paranoid" is invalid, must be one of:
+
+  • basic
+  • strict`)
+	})
+
+	t.Run("report only without directives", func(t *testing.T) {
+		c := &Security{CSP: CSP{ReportOnly: true}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.csp.directives are required for reporting`)
+	})
+}
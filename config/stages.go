@@ -44,9 +44,10 @@ func (s *Stage) Validate() error {
 
 // StageOverrides config.
 type StageOverrides struct {
-	Hooks  Hooks  `json:"hooks"`
-	Lambda Lambda `json:"lambda"`
-	Proxy  Relay  `json:"proxy"`
+	Hooks     Hooks     `json:"hooks"`
+	Lambda    Lambda    `json:"lambda"`
+	Proxy     Relay     `json:"proxy"`
+	BasicAuth BasicAuth `json:"basic_auth"`
 }
 
 // Override config.
@@ -54,6 +55,7 @@ func (s *StageOverrides) Override(c *Config) {
 	s.Hooks.Override(c)
 	s.Lambda.Override(c)
 	s.Proxy.Override(c)
+	s.BasicAuth.Override(c)
 }
 
 // Stages config.
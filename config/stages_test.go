@@ -137,3 +137,22 @@ func TestStages_GetByDomain(t *testing.T) {
 
 	assert.Equal(t, prod, s.GetByDomain("gh-polls.com"))
 }
+
+func TestStageOverrides_basicAuth(t *testing.T) {
+	c := &Config{
+		BasicAuth: BasicAuth{
+			Users: map[string]string{"tobi": "hash"},
+		},
+		Stages: Stages{
+			"production": &Stage{
+				StageOverrides: StageOverrides{
+					BasicAuth: BasicAuth{Disable: true},
+				},
+			},
+		},
+	}
+
+	c.Stages["production"].Override(c)
+	assert.True(t, c.BasicAuth.Disable)
+	assert.Equal(t, map[string]string{"tobi": "hash"}, c.BasicAuth.Users)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -10,6 +11,39 @@ import (
 type Static struct {
 	// Dir containing static files.
 	Dir string `json:"dir"`
+
+	// Prefix is the path prefix at which Dir is served
+	// in server mode, such as "/public/".
+	Prefix string `json:"prefix"`
+
+	// Mounts is a list of additional directories
+	// served at path prefixes in server mode.
+	Mounts []*StaticMount `json:"mounts"`
+
+	// SPAFallback serves index.html for unknown
+	// paths without an extension, for single-page apps.
+	SPAFallback bool `json:"spa_fallback"`
+
+	// CleanURLs serves .html files without their
+	// extension, such as /about for about.html.
+	CleanURLs bool `json:"clean_urls"`
+
+	// DirectoryListing lists the files of
+	// directories without an index.html.
+	DirectoryListing bool `json:"directory_listing"`
+}
+
+// StaticMount is a directory served at a path prefix.
+type StaticMount struct {
+	// Prefix is the path prefix, such as "/assets/".
+	Prefix string `json:"prefix"`
+
+	// Dir containing static files.
+	Dir string `json:"dir"`
+
+	// Exclusive mounts respond with 404 for missing
+	// files instead of relaying the request to your app.
+	Exclusive bool `json:"exclusive"`
 }
 
 // Default implementation.
@@ -18,24 +52,79 @@ func (s *Static) Default() error {
 		s.Dir = "."
 	}
 
+	if s.Prefix != "" {
+		s.Prefix = normalizePrefix(s.Prefix)
+	}
+
+	for _, m := range s.Mounts {
+		m.Prefix = normalizePrefix(m.Prefix)
+	}
+
 	return nil
 }
 
 // Validate implementation.
 func (s *Static) Validate() error {
-	info, err := os.Stat(s.Dir)
+	if err := validateDir(s.Dir); err != nil {
+		return errors.Wrap(err, ".dir")
+	}
+
+	for i, m := range s.Mounts {
+		if m.Dir == "" {
+			return errors.Errorf(".mounts[%d]: .dir is required", i)
+		}
+
+		if err := validateDir(m.Dir); err != nil {
+			return errors.Wrapf(err, ".mounts[%d]: .dir", i)
+		}
+	}
+
+	return nil
+}
+
+// ServerMounts returns the mounts served in server mode,
+// including Dir when a Prefix is specified.
+func (s *Static) ServerMounts() []*StaticMount {
+	var mounts []*StaticMount
+
+	if s.Prefix != "" {
+		mounts = append(mounts, &StaticMount{
+			Prefix: s.Prefix,
+			Dir:    s.Dir,
+		})
+	}
+
+	return append(mounts, s.Mounts...)
+}
+
+// validateDir returns an error if the path exists and is not a directory.
+func validateDir(dir string) error {
+	info, err := os.Stat(dir)
 
 	if os.IsNotExist(err) {
 		return nil
 	}
 
 	if err != nil {
-		return errors.Wrap(err, ".dir")
+		return err
 	}
 
 	if !info.IsDir() {
-		return errors.Errorf(".dir %s is not a directory", s.Dir)
+		return errors.Errorf("%s is not a directory", dir)
 	}
 
 	return nil
 }
+
+// normalizePrefix returns the prefix with leading and trailing slashes.
+func normalizePrefix(s string) string {
+	if !strings.HasPrefix(s, "/") {
+		s = "/" + s
+	}
+
+	if !strings.HasSuffix(s, "/") {
+		s += "/"
+	}
+
+	return s
+}
@@ -26,3 +26,29 @@ func TestStatic(t *testing.T) {
 		}
 	}
 }
+
+func TestStatic_mounts(t *testing.T) {
+	cwd, _ := os.Getwd()
+
+	s := Static{
+		Dir:    cwd,
+		Prefix: "public",
+		Mounts: []*StaticMount{
+			{Prefix: "/assets", Dir: cwd, Exclusive: true},
+		},
+	}
+
+	assert.NoError(t, s.Default(), "default")
+	assert.NoError(t, s.Validate(), "validate")
+
+	mounts := s.ServerMounts()
+	assert.Len(t, mounts, 2)
+	assert.Equal(t, &StaticMount{Prefix: "/public/", Dir: cwd}, mounts[0])
+	assert.Equal(t, &StaticMount{Prefix: "/assets/", Dir: cwd, Exclusive: true}, mounts[1])
+
+	t.Run("missing dir", func(t *testing.T) {
+		s := Static{Mounts: []*StaticMount{{Prefix: "/assets"}}}
+		assert.NoError(t, s.Default(), "default")
+		assert.EqualError(t, s.Validate(), `.mounts[0]: .dir is required`)
+	})
+}
@@ -9,13 +9,18 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/apex/up"
+	"github.com/apex/up/http/access"
+	"github.com/apex/up/http/auth"
+	"github.com/apex/up/http/cache"
+	"github.com/apex/up/http/compress"
 	"github.com/apex/up/http/cors"
 	"github.com/apex/up/http/errorpages"
-	"github.com/apex/up/http/gzip"
 	"github.com/apex/up/http/headers"
 	"github.com/apex/up/http/inject"
+	"github.com/apex/up/http/jwt"
 	"github.com/apex/up/http/logs"
 	"github.com/apex/up/http/poweredby"
+	"github.com/apex/up/http/ratelimit"
 	"github.com/apex/up/http/redirects"
 	"github.com/apex/up/http/relay"
 	"github.com/apex/up/http/static"
@@ -25,7 +30,11 @@ import (
 func FromConfig(c *up.Config) (http.Handler, error) {
 	switch c.Type {
 	case "server":
-		return relay.New(c)
+		h, err := relay.New(c)
+		if err != nil {
+			return nil, err
+		}
+		return static.NewMounts(c, h), nil
 	case "static":
 		return static.New(c), nil
 	default:
@@ -37,11 +46,26 @@ func FromConfig(c *up.Config) (http.Handler, error) {
 func New(c *up.Config, h http.Handler) (http.Handler, error) {
 	h = poweredby.New("up", h)
 
-	h, err := headers.New(c, h)
+	h, err := cache.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "cache")
+	}
+
+	h, err = headers.New(c, h)
 	if err != nil {
 		return nil, errors.Wrap(err, "headers")
 	}
 
+	h, err = auth.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "auth")
+	}
+
+	h, err = jwt.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt")
+	}
+
 	h, err = errorpages.New(c, h)
 	if err != nil {
 		return nil, errors.Wrap(err, "error pages")
@@ -59,7 +83,17 @@ func New(c *up.Config, h http.Handler) (http.Handler, error) {
 		return nil, errors.Wrap(err, "redirects")
 	}
 
-	h = gzip.New(c, h)
+	h, err = ratelimit.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "rate limit")
+	}
+
+	h, err = access.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "access")
+	}
+
+	h = compress.New(c, h)
 
 	h, err = logs.New(c, h)
 	if err != nil {
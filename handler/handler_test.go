@@ -71,6 +71,7 @@ func TestStatic(t *testing.T) {
 	header.Add("Content-Type", "text/html; charset=utf-8")
 	header.Add("Accept-Ranges", "bytes")
 	header.Add("Vary", "Accept-Encoding")
+	header.Add("ETag", `"648a6a6ffffdaa0badb23b8baf90b6168dd16b3a"`)
 
 	assert.Equal(t, header, actual)
 }
@@ -0,0 +1,194 @@
+// Package access provides IP address allow and deny rules.
+package access
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/fanyang01/radix"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/logs"
+)
+
+// log context.
+var ctx = logs.Plugin("access")
+
+// Networks is a list of networks.
+type Networks []*net.IPNet
+
+// Contains returns true if ip is within one of the networks.
+func (n Networks) Contains(ip net.IP) bool {
+	for _, v := range n {
+		if v.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rule is a compiled access rule.
+type rule struct {
+	*config.AccessRule
+	paths *radix.PatternTrie
+	allow Networks
+	deny  Networks
+}
+
+// Allowed returns true if the ip is allowed, unknown addresses are denied.
+func (r *rule) Allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	if r.deny.Contains(ip) {
+		return false
+	}
+
+	return len(r.allow) == 0 || r.allow.Contains(ip)
+}
+
+// Match returns true if the rule applies to the path.
+func (r *rule) Match(path string) bool {
+	_, ok := r.paths.Lookup(path)
+	return ok
+}
+
+// New access handler.
+func New(c *up.Config, next http.Handler) (http.Handler, error) {
+	if len(c.Access.Rules) == 0 {
+		return next, nil
+	}
+
+	trusted, err := Parse(c.Access.TrustedProxies)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing trusted proxies")
+	}
+
+	rules, err := compile(c.Access.Rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling rules")
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := ClientIP(r, trusted)
+
+		for _, rule := range rules {
+			if !rule.Match(r.URL.Path) || rule.Allowed(ip) {
+				continue
+			}
+
+			ctx.WithFields(log.Fields{
+				"ip":     ip.String(),
+				"path":   r.URL.Path,
+				"rule":   rule.Path,
+				"status": rule.Status,
+			}).Warn("blocked")
+
+			http.Error(w, http.StatusText(rule.Status), rule.Status)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return h, nil
+}
+
+// compile the rules.
+func compile(rules []*config.AccessRule) ([]*rule, error) {
+	var list []*rule
+
+	for _, r := range rules {
+		allow, err := Parse(r.Allow)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s allow", r.Path)
+		}
+
+		deny, err := Parse(r.Deny)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s deny", r.Path)
+		}
+
+		paths := radix.NewPatternTrie()
+		paths.Add(r.Path, true)
+
+		list = append(list, &rule{
+			AccessRule: r,
+			paths:      paths,
+			allow:      allow,
+			deny:       deny,
+		})
+	}
+
+	return list, nil
+}
+
+// Parse a list of addresses or CIDR ranges.
+func Parse(list []string) (Networks, error) {
+	var n Networks
+
+	for _, s := range list {
+		if ip := net.ParseIP(s); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			n = append(n, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+
+		n = append(n, cidr)
+	}
+
+	return n, nil
+}
+
+// ClientIP returns the client ip address of the request. When the
+// remote address is a trusted proxy the X-Forwarded-For header field
+// is walked from right to left, returning the first untrusted address.
+func ClientIP(r *http.Request, trusted Networks) net.IP {
+	ip := parseIP(r.RemoteAddr)
+
+	if ip == nil || !trusted.Contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, v := range r.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+
+		ip = hop
+
+		if !trusted.Contains(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// parseIP parses an address with an optional port.
+func parseIP(s string) net.IP {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	return net.ParseIP(s)
+}
@@ -0,0 +1,121 @@
+package access
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+var app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("Hello"))
+})
+
+func request(h http.Handler, path, addr, forwarded string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = addr
+
+	if forwarded != "" {
+		req.Header.Set("X-Forwarded-For", forwarded)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestAccess(t *testing.T) {
+	c := &up.Config{
+		Access: config.Access{
+			TrustedProxies: []string{"10.0.0.0/8"},
+			Rules: []*config.AccessRule{
+				{
+					Deny: []string{"203.0.113.7", "2001:db8::/32"},
+				},
+				{
+					Path:   "/admin/*",
+					Allow:  []string{"192.168.1.0/24"},
+					Status: 404,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Access.Default(), "default")
+	assert.NoError(t, c.Access.Validate(), "validate")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	t.Run("allowed", func(t *testing.T) {
+		res := request(h, "/", "198.51.100.1", "")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Hello", res.Body.String())
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		res := request(h, "/", "203.0.113.7", "")
+		assert.Equal(t, 403, res.Code)
+
+		res = request(h, "/", "[2001:db8::1]:1234", "")
+		assert.Equal(t, 403, res.Code)
+	})
+
+	t.Run("allow list", func(t *testing.T) {
+		res := request(h, "/admin/users", "192.168.1.20", "")
+		assert.Equal(t, 200, res.Code)
+
+		res = request(h, "/admin/users", "198.51.100.1", "")
+		assert.Equal(t, 404, res.Code)
+	})
+
+	t.Run("untrusted forwarded for", func(t *testing.T) {
+		res := request(h, "/admin/users", "198.51.100.1", "192.168.1.20")
+		assert.Equal(t, 404, res.Code)
+	})
+
+	t.Run("trusted forwarded for", func(t *testing.T) {
+		res := request(h, "/admin/users", "10.1.1.1", "192.168.1.20, 10.2.2.2")
+		assert.Equal(t, 200, res.Code)
+
+		res = request(h, "/", "10.1.1.1", "203.0.113.7")
+		assert.Equal(t, 403, res.Code)
+	})
+
+	t.Run("unknown address", func(t *testing.T) {
+		res := request(h, "/", "", "")
+		assert.Equal(t, 403, res.Code)
+	})
+}
+
+func TestAccess_disabled(t *testing.T) {
+	c := &up.Config{}
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	res := request(h, "/", "203.0.113.7", "")
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := Parse([]string{"10.0.0.0/8", "172.16.0.1"})
+	assert.NoError(t, err, "parse")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "172.16.0.1:443"
+	req.Header.Add("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+	req.Header.Add("X-Forwarded-For", "10.0.0.5")
+	assert.Equal(t, net.ParseIP("2.2.2.2"), ClientIP(req, trusted))
+
+	req.RemoteAddr = "3.3.3.3:443"
+	assert.Equal(t, net.ParseIP("3.3.3.3"), ClientIP(req, trusted))
+
+	req.RemoteAddr = "10.0.0.1"
+	req.Header.Set("X-Forwarded-For", "10.0.0.2")
+	assert.Equal(t, net.ParseIP("10.0.0.2"), ClientIP(req, trusted))
+}
@@ -0,0 +1,93 @@
+// Package auth provides basic authentication.
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/fanyang01/radix"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/logs"
+)
+
+// log context.
+var ctx = logs.Plugin("auth")
+
+// Environment variables providing credentials.
+const (
+	UserEnv     = "UP_BASIC_AUTH_USER"
+	PasswordEnv = "UP_BASIC_AUTH_PASSWORD"
+)
+
+// New basic auth handler.
+func New(c *up.Config, next http.Handler) (http.Handler, error) {
+	conf := c.BasicAuth
+	user, pass := os.Getenv(UserEnv), os.Getenv(PasswordEnv)
+
+	if conf.Disable || (len(conf.Users) == 0 && user == "") {
+		return next, nil
+	}
+
+	paths := compile(conf.Paths)
+	exclude := compile(conf.Exclude)
+	challenge := fmt.Sprintf("Basic realm=%q", conf.Realm)
+
+	// authorized returns true if the credentials are valid.
+	authorized := func(u, p string) bool {
+		if user != "" && subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1 {
+			return subtle.ConstantTimeCompare([]byte(p), []byte(pass)) == 1
+		}
+
+		hash, ok := conf.Users[u]
+		if !ok {
+			return false
+		}
+
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(p)) == nil
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+
+		if !match(paths, path) || match(exclude, path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		u, p, ok := r.BasicAuth()
+		if ok && authorized(u, p) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok {
+			ctx.WithField("user", u).Warn("invalid credentials")
+		}
+
+		w.Header().Set("WWW-Authenticate", challenge)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+
+	return h, nil
+}
+
+// compile path patterns to a trie.
+func compile(patterns []string) *radix.PatternTrie {
+	t := radix.NewPatternTrie()
+
+	for _, p := range patterns {
+		t.Add(p, true)
+	}
+
+	return t
+}
+
+// match returns true if the path matches a pattern.
+func match(t *radix.PatternTrie, path string) bool {
+	_, ok := t.Lookup(path)
+	return ok
+}
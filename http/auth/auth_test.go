@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+// hash of "ferret".
+var hash = "$2a$04$5OWFBadMRVV8N75c.UPWfugzPoypUjKf/KgQ5d/znkdUe56r4vprK"
+
+var app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("Hello"))
+})
+
+func request(h http.Handler, path, user, pass string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestAuth(t *testing.T) {
+	c := &up.Config{
+		BasicAuth: config.BasicAuth{
+			Realm:   "Staging",
+			Users:   map[string]string{"tobi": hash},
+			Exclude: []string{"/health"},
+		},
+	}
+
+	assert.NoError(t, c.BasicAuth.Default(), "default")
+	assert.NoError(t, c.BasicAuth.Validate(), "validate")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	t.Run("missing credentials", func(t *testing.T) {
+		res := request(h, "/", "", "")
+		assert.Equal(t, 401, res.Code)
+		assert.Equal(t, `Basic realm="Staging"`, res.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		res := request(h, "/", "tobi", "cat")
+		assert.Equal(t, 401, res.Code)
+
+		res = request(h, "/", "loki", "ferret")
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("valid credentials", func(t *testing.T) {
+		res := request(h, "/", "tobi", "ferret")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Hello", res.Body.String())
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		res := request(h, "/health", "", "")
+		assert.Equal(t, 200, res.Code)
+	})
+}
+
+func TestAuth_paths(t *testing.T) {
+	c := &up.Config{
+		BasicAuth: config.BasicAuth{
+			Users: map[string]string{"tobi": hash},
+			Paths: []string{"/admin/*"},
+		},
+	}
+
+	assert.NoError(t, c.BasicAuth.Default(), "default")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	assert.Equal(t, 200, request(h, "/", "", "").Code)
+	assert.Equal(t, 401, request(h, "/admin/users", "", "").Code)
+	assert.Equal(t, 200, request(h, "/admin/users", "tobi", "ferret").Code)
+}
+
+func TestAuth_env(t *testing.T) {
+	os.Setenv(UserEnv, "admin")
+	os.Setenv(PasswordEnv, "secret")
+	defer os.Unsetenv(UserEnv)
+	defer os.Unsetenv(PasswordEnv)
+
+	c := &up.Config{}
+	assert.NoError(t, c.BasicAuth.Default(), "default")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	assert.Equal(t, 401, request(h, "/", "", "").Code)
+	assert.Equal(t, 401, request(h, "/", "admin", "nope").Code)
+	assert.Equal(t, 200, request(h, "/", "admin", "secret").Code)
+}
+
+func TestAuth_disabled(t *testing.T) {
+	c := &up.Config{
+		BasicAuth: config.BasicAuth{
+			Disable: true,
+			Users:   map[string]string{"tobi": hash},
+		},
+	}
+
+	assert.NoError(t, c.BasicAuth.Default(), "default")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	assert.Equal(t, 200, request(h, "/", "", "").Code)
+}
@@ -0,0 +1,359 @@
+// Package cache provides an in-memory response cache
+// honoring the Cache-Control header field.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fanyang01/radix"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/cache"
+	"github.com/apex/up/internal/logs"
+)
+
+// log context.
+var ctx = logs.Plugin("cache")
+
+// entry is a cached response.
+type entry struct {
+	status int
+	header http.Header
+	body   []byte
+	etag   string
+	stored time.Time
+	ttl    time.Duration
+	stale  time.Duration
+}
+
+// size returns the approximate size of the entry.
+func (e *entry) size() int64 {
+	n := len(e.body)
+	for k, v := range e.header {
+		n += len(k)
+		for _, s := range v {
+			n += len(s)
+		}
+	}
+	return int64(n)
+}
+
+// recorder is a buffered response writer.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header implementation.
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implementation.
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+// Write implementation.
+func (r *recorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// Cache is a response cache handler.
+type Cache struct {
+	rules *radix.PatternTrie
+	lru   *cache.LRU
+	next  http.Handler
+	now   func() time.Time
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+// New cache handler.
+func New(c *up.Config, next http.Handler) (http.Handler, error) {
+	if len(c.Cache.Rules) == 0 {
+		return next, nil
+	}
+
+	rules := radix.NewPatternTrie()
+	for path, rule := range c.Cache.Rules {
+		rules.Add(path, rule)
+	}
+
+	h := &Cache{
+		rules:        rules,
+		lru:          cache.NewLRU(c.Cache.Size()),
+		next:         next,
+		now:          time.Now,
+		revalidating: make(map[string]bool),
+	}
+
+	return h, nil
+}
+
+// ServeHTTP implementation.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	v, ok := c.rules.Lookup(r.URL.Path)
+	if !ok || v.(*config.CacheRule).Disable {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	rule := v.(*config.CacheRule)
+	key := Key(r, rule.Vary)
+
+	if v, ok := c.lru.Get(key); ok {
+		e := v.(*entry)
+		age := c.now().Sub(e.stored)
+
+		if age < e.ttl {
+			c.write(w, r, e, "HIT")
+			return
+		}
+
+		if age < e.ttl+e.stale {
+			c.revalidate(key, r, rule)
+			c.write(w, r, e, "HIT")
+			return
+		}
+
+		c.lru.Remove(key)
+	}
+
+	if r.Method == "HEAD" {
+		w.Header().Set("X-Cache", "MISS")
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	res, e := c.fetch(r, rule)
+	if e != nil {
+		c.lru.Set(key, e, e.size())
+		c.write(w, r, e, "MISS")
+		return
+	}
+
+	copyHeader(w.Header(), res.header)
+	w.Header().Set("X-Cache", "MISS")
+	w.WriteHeader(res.status)
+	w.Write(res.body.Bytes())
+}
+
+// fetch a response from the next handler, returning
+// an entry when it may be stored.
+func (c *Cache) fetch(r *http.Request, rule *config.CacheRule) (*recorder, *entry) {
+	res := &recorder{header: make(http.Header)}
+	c.next.ServeHTTP(res, r)
+
+	if res.status == 0 {
+		res.status = http.StatusOK
+	}
+
+	ttl, stale, ok := storable(r, res, rule)
+	if !ok {
+		return res, nil
+	}
+
+	etag := res.header.Get("ETag")
+	if etag == "" {
+		sum := sha1.Sum(res.body.Bytes())
+		etag = `"` + hex.EncodeToString(sum[:]) + `"`
+		res.header.Set("ETag", etag)
+	}
+
+	return res, &entry{
+		status: res.status,
+		header: res.header,
+		body:   res.body.Bytes(),
+		etag:   etag,
+		stored: c.now(),
+		ttl:    ttl,
+		stale:  stale,
+	}
+}
+
+// revalidate the entry of key in the background.
+func (c *Cache) revalidate(key string, r *http.Request, rule *config.CacheRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.revalidating[key] {
+		return
+	}
+
+	c.revalidating[key] = true
+
+	req := r.WithContext(context.Background())
+	req.Method = "GET"
+	req.Header = make(http.Header)
+	copyHeader(req.Header, r.Header)
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, key)
+			c.mu.Unlock()
+		}()
+
+		ctx.WithField("path", req.URL.Path).Debug("revalidating")
+
+		if _, e := c.fetch(req, rule); e != nil {
+			c.lru.Set(key, e, e.size())
+		} else {
+			c.lru.Remove(key)
+		}
+	}()
+}
+
+// write the entry, responding with a 304 when the etag matches.
+func (c *Cache) write(w http.ResponseWriter, r *http.Request, e *entry, status string) {
+	header := w.Header()
+	copyHeader(header, e.header)
+	header.Set("X-Cache", status)
+
+	if status == "HIT" {
+		age := c.now().Sub(e.stored) / time.Second
+		header.Set("Age", strconv.Itoa(int(age)))
+	}
+
+	if match(r.Header.Get("If-None-Match"), e.etag) {
+		for _, name := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+			header.Del(name)
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(e.status)
+
+	if r.Method != "HEAD" {
+		w.Write(e.body)
+	}
+}
+
+// storable returns the freshness lifetime and stale-while-revalidate
+// duration of the response, and false when it may not be stored.
+func storable(r *http.Request, res *recorder, rule *config.CacheRule) (ttl, stale time.Duration, ok bool) {
+	if res.status != http.StatusOK || res.header.Get("Set-Cookie") != "" {
+		return
+	}
+
+	if !varies(res.header, rule.Vary) {
+		return
+	}
+
+	cc := cache.ParseControl(res.header.Get("Cache-Control"))
+
+	if cc.NoStore || cc.NoCache || cc.Private {
+		return
+	}
+
+	if r.Header.Get("Authorization") != "" && !cc.Public && cc.SMaxAge == nil {
+		return
+	}
+
+	ttl = cc.TTL(time.Duration(rule.MaxAge))
+	if ttl <= 0 {
+		return
+	}
+
+	return ttl, cc.StaleWhileRevalidate, true
+}
+
+// varies returns true if the Vary header field of the response is
+// covered by the cache key. Accept-Encoding is ignored as responses
+// are compressed after caching.
+func varies(header http.Header, vary []string) bool {
+	for _, v := range header["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+
+			if name == "" || strings.EqualFold(name, "Accept-Encoding") {
+				continue
+			}
+
+			if name == "*" || !contains(vary, name) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Key returns the cache key for the request, comprised of the path,
+// normalized query string and the values of the given header fields.
+func Key(r *http.Request, vary []string) string {
+	var b bytes.Buffer
+
+	b.WriteString(r.URL.Path)
+
+	if q := r.URL.Query(); len(q) > 0 {
+		b.WriteString("?")
+		b.WriteString(q.Encode())
+	}
+
+	for _, name := range vary {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(": ")
+		b.WriteString(strings.Join(r.Header[http.CanonicalHeaderKey(name)], ", "))
+	}
+
+	return b.String()
+}
+
+// match returns true if the If-None-Match header field matches the etag.
+func match(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+
+		if v == "*" || strings.TrimPrefix(v, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains returns true if the header field name is present in list.
+func contains(list []string, name string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyHeader copies header fields from src to dst.
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = append([]string(nil), v...)
+	}
+}
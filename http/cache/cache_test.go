@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+// counter app responding with the number of requests made.
+type counter struct {
+	n       int32
+	control string
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&c.n, 1)
+
+	switch r.URL.Path {
+	case "/cookie":
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: "tobi"})
+	case "/missing":
+		w.WriteHeader(404)
+	case "/private":
+		w.Header().Set("Cache-Control", "private, max-age=60")
+	case "/lang":
+		w.Header().Set("Vary", "Accept-Language")
+	default:
+		if c.control != "" {
+			w.Header().Set("Cache-Control", c.control)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{ "count": %d }`, n)
+}
+
+func request(h http.Handler, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func newCache(t testing.TB, app http.Handler, rules map[string]*config.CacheRule) *Cache {
+	c := &up.Config{
+		Cache: config.Cache{
+			Rules: rules,
+		},
+	}
+
+	assert.NoError(t, c.Cache.Default(), "default")
+	assert.NoError(t, c.Cache.Validate(), "validate")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	return h.(*Cache)
+}
+
+func TestCache(t *testing.T) {
+	app := &counter{}
+	h := newCache(t, app, map[string]*config.CacheRule{
+		"/*":      {MaxAge: config.Duration(time.Minute), Vary: []string{"Accept-Language"}},
+		"/live/*": {Disable: true},
+	})
+
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	t.Run("miss", func(t *testing.T) {
+		res := request(h, "GET", "/pets?b=2&a=1", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "MISS", res.Header().Get("X-Cache"))
+		assert.Equal(t, `{ "count": 1 }`, res.Body.String())
+		assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+		assert.NotEmpty(t, res.Header().Get("ETag"))
+	})
+
+	t.Run("hit", func(t *testing.T) {
+		now = now.Add(10 * time.Second)
+		res := request(h, "GET", "/pets?a=1&b=2", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "HIT", res.Header().Get("X-Cache"))
+		assert.Equal(t, "10", res.Header().Get("Age"))
+		assert.Equal(t, `{ "count": 1 }`, res.Body.String())
+	})
+
+	t.Run("head", func(t *testing.T) {
+		res := request(h, "HEAD", "/pets?a=1&b=2", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "HIT", res.Header().Get("X-Cache"))
+		assert.Equal(t, "", res.Body.String())
+	})
+
+	t.Run("conditional", func(t *testing.T) {
+		etag := request(h, "GET", "/pets?a=1&b=2", nil).Header().Get("ETag")
+		res := request(h, "GET", "/pets?a=1&b=2", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, 304, res.Code)
+		assert.Equal(t, etag, res.Header().Get("ETag"))
+		assert.Equal(t, "", res.Body.String())
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		res := request(h, "GET", "/lang", map[string]string{"Accept-Language": "en"})
+		assert.Equal(t, `{ "count": 2 }`, res.Body.String())
+
+		res = request(h, "GET", "/lang", map[string]string{"Accept-Language": "fr"})
+		assert.Equal(t, "MISS", res.Header().Get("X-Cache"))
+		assert.Equal(t, `{ "count": 3 }`, res.Body.String())
+
+		res = request(h, "GET", "/lang", map[string]string{"Accept-Language": "en"})
+		assert.Equal(t, "HIT", res.Header().Get("X-Cache"))
+		assert.Equal(t, `{ "count": 2 }`, res.Body.String())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		res := request(h, "GET", "/pets?a=1&b=2", nil)
+		assert.Equal(t, "MISS", res.Header().Get("X-Cache"))
+		assert.Equal(t, `{ "count": 4 }`, res.Body.String())
+	})
+
+	t.Run("disabled path", func(t *testing.T) {
+		request(h, "GET", "/live/scores", nil)
+		res := request(h, "GET", "/live/scores", nil)
+		assert.Equal(t, "", res.Header().Get("X-Cache"))
+		assert.Equal(t, `{ "count": 6 }`, res.Body.String())
+	})
+
+	t.Run("not storable", func(t *testing.T) {
+		for _, path := range []string{"/cookie", "/missing", "/private"} {
+			request(h, "GET", path, nil)
+			res := request(h, "GET", path, nil)
+			assert.Equal(t, "MISS", res.Header().Get("X-Cache"), path)
+		}
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		auth := map[string]string{"Authorization": "Bearer token"}
+		request(h, "GET", "/account", auth)
+		res := request(h, "GET", "/account", auth)
+		assert.Equal(t, "MISS", res.Header().Get("X-Cache"))
+	})
+
+	t.Run("post", func(t *testing.T) {
+		res := request(h, "POST", "/pets", nil)
+		assert.Equal(t, "", res.Header().Get("X-Cache"))
+	})
+}
+
+func TestCache_control(t *testing.T) {
+	app := &counter{control: "public, max-age=0, s-maxage=30"}
+	h := newCache(t, app, map[string]*config.CacheRule{"/*": {}})
+
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	assert.Equal(t, "MISS", request(h, "GET", "/", nil).Header().Get("X-Cache"))
+
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, "HIT", request(h, "GET", "/", nil).Header().Get("X-Cache"))
+
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, "MISS", request(h, "GET", "/", nil).Header().Get("X-Cache"))
+
+	t.Run("no-store", func(t *testing.T) {
+		app := &counter{control: "no-store"}
+		h := newCache(t, app, map[string]*config.CacheRule{"/*": {MaxAge: config.Duration(time.Minute)}})
+		request(h, "GET", "/", nil)
+		assert.Equal(t, "MISS", request(h, "GET", "/", nil).Header().Get("X-Cache"))
+	})
+
+	t.Run("without max-age", func(t *testing.T) {
+		app := &counter{}
+		h := newCache(t, app, map[string]*config.CacheRule{"/*": {}})
+		request(h, "GET", "/", nil)
+		assert.Equal(t, "MISS", request(h, "GET", "/", nil).Header().Get("X-Cache"))
+	})
+}
+
+func TestCache_staleWhileRevalidate(t *testing.T) {
+	app := &counter{control: "max-age=10, stale-while-revalidate=60"}
+	h := newCache(t, app, map[string]*config.CacheRule{"/*": {}})
+
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	res := request(h, "GET", "/", nil)
+	assert.Equal(t, `{ "count": 1 }`, res.Body.String())
+
+	now = now.Add(30 * time.Second)
+	res = request(h, "GET", "/", nil)
+	assert.Equal(t, "HIT", res.Header().Get("X-Cache"))
+	assert.Equal(t, `{ "count": 1 }`, res.Body.String())
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&app.n) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		n := len(h.revalidating)
+		h.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	res = request(h, "GET", "/", nil)
+	assert.Equal(t, "HIT", res.Header().Get("X-Cache"))
+	assert.Equal(t, `{ "count": 2 }`, res.Body.String())
+
+	now = now.Add(2 * time.Minute)
+	res = request(h, "GET", "/", nil)
+	assert.Equal(t, "MISS", res.Header().Get("X-Cache"))
+	assert.Equal(t, `{ "count": 3 }`, res.Body.String())
+}
+
+func TestCache_size(t *testing.T) {
+	app := &counter{control: "max-age=60"}
+	c := &up.Config{
+		Cache: config.Cache{
+			MaxSize: "200B",
+			Rules:   map[string]*config.CacheRule{"/*": {}},
+		},
+	}
+
+	assert.NoError(t, c.Cache.Default(), "default")
+	assert.NoError(t, c.Cache.Validate(), "validate")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	request(h, "GET", "/a", nil)
+	request(h, "GET", "/b", nil)
+	assert.Equal(t, "HIT", request(h, "GET", "/b", nil).Header().Get("X-Cache"))
+	assert.Equal(t, "MISS", request(h, "GET", "/a", nil).Header().Get("X-Cache"))
+}
+
+func TestKey(t *testing.T) {
+	a := httptest.NewRequest("GET", "/pets?b=2&a=1", nil)
+	b := httptest.NewRequest("GET", "/pets?a=1&b=2", nil)
+	assert.Equal(t, Key(a, nil), Key(b, nil))
+
+	a.Header.Set("Accept-Language", "en")
+	assert.Equal(t, "/pets?a=1&b=2\nAccept-Language: en", Key(a, []string{"accept-language"}))
+}
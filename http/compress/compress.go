@@ -0,0 +1,241 @@
+// Package compress provides brotli and gzip compression support.
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+// response wrapper.
+type response struct {
+	http.ResponseWriter
+	config   *config.Compression
+	encoding string
+	head     bool
+	status   int
+	buf      []byte
+	decided  bool
+	enc      io.WriteCloser
+}
+
+// WriteHeader implementation.
+func (r *response) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+// Write implementation.
+func (r *response) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	if !r.decided {
+		r.buf = append(r.buf, b...)
+
+		if len(r.buf) < r.config.MinSize {
+			return len(b), nil
+		}
+
+		if err := r.decide(true); err != nil {
+			return 0, err
+		}
+
+		return len(b), nil
+	}
+
+	if r.enc != nil {
+		return r.enc.Write(b)
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+// Close flushes buffered data and closes the encoder.
+func (r *response) Close() error {
+	if !r.decided {
+		if err := r.decide(false); err != nil {
+			return err
+		}
+	}
+
+	if r.enc != nil {
+		return r.enc.Close()
+	}
+
+	return nil
+}
+
+// decide whether or not to compress, and write the buffered data.
+func (r *response) decide(large bool) error {
+	r.decided = true
+	w := r.ResponseWriter
+	h := w.Header()
+
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	if h.Get("Content-Type") == "" && len(r.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(r.buf))
+	}
+
+	if large && r.compressible() {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", r.encoding)
+		r.enc = encoder(w, r.encoding, r.config.Level)
+		w.WriteHeader(r.status)
+		_, err := r.enc.Write(r.buf)
+		return err
+	}
+
+	w.WriteHeader(r.status)
+
+	if len(r.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.Write(r.buf)
+	return err
+}
+
+// compressible returns true if the response should be compressed.
+func (r *response) compressible() bool {
+	h := r.Header()
+
+	if r.head || h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	switch r.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	kind := h.Get("Content-Type")
+
+	if len(r.config.ContentTypes) > 0 && !MatchType(r.config.ContentTypes, kind) {
+		return false
+	}
+
+	return !MatchType(r.config.ExcludeContentTypes, kind)
+}
+
+// New compression handler.
+func New(c *up.Config, next http.Handler) http.Handler {
+	conf := &c.Compression
+
+	if conf.Disable {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := Negotiate(r.Header.Get("Accept-Encoding"), conf.Algorithms)
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res := &response{
+			ResponseWriter: w,
+			config:         conf,
+			encoding:       encoding,
+			head:           r.Method == "HEAD",
+		}
+
+		next.ServeHTTP(res, r)
+		res.Close()
+	})
+}
+
+// encoder returns an encoder writing to w.
+func encoder(w io.Writer, encoding string, level int) io.WriteCloser {
+	if encoding == "br" {
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w, level)
+	}
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	gz, _ := gzip.NewWriterLevel(w, level)
+	return gz
+}
+
+// Negotiate returns the preferred encoding accepted by the client, or an
+// empty string. Ties in quality are broken by the order of encodings.
+func Negotiate(header string, encodings []string) string {
+	accepted := make(map[string]float64)
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		accepted[name] = q
+	}
+
+	var best string
+	var quality float64
+
+	for _, e := range encodings {
+		q, ok := accepted[e]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+
+		if ok && q > quality {
+			best, quality = e, q
+		}
+	}
+
+	return best
+}
+
+// MatchType returns true if the content type matches
+// one of the patterns, such as "text/*" or "text/html".
+func MatchType(patterns []string, kind string) bool {
+	mt, _, err := mime.ParseMediaType(kind)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+
+		if p == mt {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,189 @@
+package compress
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+)
+
+var body = strings.Repeat("так", 5000)
+
+var hello = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, body)
+})
+
+func request(h http.Handler, method, accept string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/", nil)
+
+	if accept != "" {
+		req.Header.Set("Accept-Encoding", accept)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestCompress(t *testing.T) {
+	c, err := up.ParseConfigString(`{ "name": "app" }`)
+	assert.NoError(t, err, "config")
+
+	h := New(c, hello)
+
+	t.Run("accepts gzip", func(t *testing.T) {
+		res := request(h, "GET", "gzip")
+
+		header := make(http.Header)
+		header.Add("Content-Type", "text/plain; charset=utf-8")
+		header.Add("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, header, res.HeaderMap)
+
+		gz, err := gzip.NewReader(res.Body)
+		assert.NoError(t, err, "reader")
+
+		b, err := ioutil.ReadAll(gz)
+		assert.NoError(t, err, "reading")
+		assert.NoError(t, gz.Close(), "close")
+
+		assert.Equal(t, body, string(b))
+	})
+
+	t.Run("accepts brotli", func(t *testing.T) {
+		res := request(h, "GET", "gzip, deflate, br")
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "br", res.Header().Get("Content-Encoding"))
+
+		b, err := ioutil.ReadAll(brotli.NewReader(res.Body))
+		assert.NoError(t, err, "reading")
+		assert.Equal(t, body, string(b))
+	})
+
+	t.Run("accepts identity", func(t *testing.T) {
+		res := request(h, "GET", "")
+
+		header := make(http.Header)
+		header.Add("Content-Type", "text/plain; charset=utf-8")
+		header.Add("Vary", "Accept-Encoding")
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, header, res.HeaderMap)
+
+		assert.Equal(t, body, res.Body.String())
+	})
+
+	t.Run("head", func(t *testing.T) {
+		res := request(h, "HEAD", "gzip")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+	})
+}
+
+func TestCompress_minSize(t *testing.T) {
+	c, err := up.ParseConfigString(`{ "name": "app" }`)
+	assert.NoError(t, err, "config")
+
+	h := New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(201)
+		fmt.Fprint(w, "Hello")
+	}))
+
+	res := request(h, "GET", "gzip")
+	assert.Equal(t, 201, res.Code)
+	assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/plain; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, "Hello", res.Body.String())
+}
+
+func TestCompress_contentTypes(t *testing.T) {
+	image := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, body)
+	})
+
+	t.Run("excluded by default", func(t *testing.T) {
+		c, err := up.ParseConfigString(`{ "name": "app" }`)
+		assert.NoError(t, err, "config")
+
+		res := request(New(c, image), "GET", "gzip")
+		assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, res.Body.String())
+	})
+
+	t.Run("included", func(t *testing.T) {
+		c, err := up.ParseConfigString(`{
+			"name": "app",
+			"compression": {
+				"content_types": ["application/json"]
+			}
+		}`)
+		assert.NoError(t, err, "config")
+
+		res := request(New(c, hello), "GET", "gzip")
+		assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+	})
+}
+
+func TestCompress_config(t *testing.T) {
+	t.Run("algorithms", func(t *testing.T) {
+		c, err := up.ParseConfigString(`{
+			"name": "app",
+			"compression": {
+				"algorithms": ["gzip"],
+				"level": 9
+			}
+		}`)
+		assert.NoError(t, err, "config")
+
+		res := request(New(c, hello), "GET", "br, gzip")
+		assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		c, err := up.ParseConfigString(`{
+			"name": "app",
+			"compression": {
+				"disable": true
+			}
+		}`)
+		assert.NoError(t, err, "config")
+
+		res := request(New(c, hello), "GET", "gzip")
+		assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+		assert.Equal(t, "", res.Header().Get("Vary"))
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	algorithms := []string{"br", "gzip"}
+
+	assert.Equal(t, "", Negotiate("", algorithms))
+	assert.Equal(t, "", Negotiate("deflate", algorithms))
+	assert.Equal(t, "gzip", Negotiate("gzip", algorithms))
+	assert.Equal(t, "br", Negotiate("gzip, br", algorithms))
+	assert.Equal(t, "gzip", Negotiate("br;q=0.5, gzip", algorithms))
+	assert.Equal(t, "gzip", Negotiate("br;q=0, *", algorithms))
+	assert.Equal(t, "br", Negotiate("*", algorithms))
+	assert.Equal(t, "", Negotiate("br;q=0, gzip;q=0", algorithms))
+}
+
+func TestMatchType(t *testing.T) {
+	patterns := []string{"text/*", "application/json"}
+
+	assert.True(t, MatchType(patterns, "text/html; charset=utf-8"))
+	assert.True(t, MatchType(patterns, "application/json"))
+	assert.False(t, MatchType(patterns, "application/javascript"))
+	assert.False(t, MatchType(patterns, ""))
+}
@@ -10,6 +10,7 @@ import (
 	hdr "github.com/tj/go-headers"
 
 	"github.com/apex/up"
+	"github.com/apex/up/internal/csp"
 	"github.com/apex/up/internal/header"
 )
 
@@ -34,7 +35,32 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	log.Debugf("header rules from _headers file: %d", len(rulesFromFile))
 	log.Debugf("header rules from up.json: %d", len(c.Headers))
 
+	security := c.Security
+	policy := security.CSP.Directives
+	nonced := policy.Nonced()
+
+	field := "Content-Security-Policy"
+	if security.CSP.ReportOnly {
+		field += "-Report-Only"
+	}
+
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range security.Headers {
+			if v != "" {
+				w.Header().Set(k, v)
+			}
+		}
+
+		if len(policy) > 0 {
+			var nonce string
+
+			if nonced {
+				r, nonce = csp.WithNonce(r)
+			}
+
+			w.Header().Set(field, policy.String(nonce))
+		}
+
 		fields := rules.Lookup(r.URL.Path)
 
 		for k, v := range fields {
@@ -1,6 +1,7 @@
 package headers
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
@@ -8,7 +9,9 @@ import (
 	"github.com/tj/assert"
 	"github.com/apex/up"
 
+	"github.com/apex/up/config"
 	"github.com/apex/up/http/static"
+	"github.com/apex/up/internal/csp"
 	"github.com/apex/up/internal/header"
 )
 
@@ -52,3 +55,88 @@ func TestHeaders(t *testing.T) {
 		assert.Equal(t, "body { color: red }\n", res.Body.String())
 	})
 }
+
+func TestHeaders_security(t *testing.T) {
+	os.Chdir("testdata")
+	defer os.Chdir("..")
+
+	c := &up.Config{
+		Headers: header.Rules{
+			"/*.css": {
+				"X-Frame-Options": "SAMEORIGIN",
+			},
+		},
+		Security: config.Security{
+			Preset: "strict",
+			Headers: map[string]string{
+				"Referrer-Policy": "",
+			},
+		},
+	}
+
+	assert.NoError(t, c.Security.Default(), "default")
+
+	var nonce string
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, _ = csp.FromContext(r.Context())
+		static.New(c).ServeHTTP(w, r)
+	})
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	t.Run("preset", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "DENY", res.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "nosniff", res.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "", res.Header().Get("Referrer-Policy"))
+		assert.NotEmpty(t, nonce)
+		assert.Contains(t, res.Header().Get("Content-Security-Policy"), "script-src 'self' 'nonce-"+nonce+"'")
+	})
+
+	t.Run("nonce per request", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		prev := nonce
+
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		assert.NotEqual(t, prev, nonce)
+	})
+
+	t.Run("header rules take precedence", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/style.css", nil)
+
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, "SAMEORIGIN", res.Header().Get("X-Frame-Options"))
+	})
+
+	t.Run("report only", func(t *testing.T) {
+		c := &up.Config{
+			Security: config.Security{
+				CSP: config.CSP{
+					Directives: csp.Policy{"default-src": {"'self'"}},
+					ReportOnly: true,
+					ReportURI:  "/csp-report",
+				},
+			},
+		}
+
+		assert.NoError(t, c.Security.Default(), "default")
+
+		h, err := New(c, static.New(c))
+		assert.NoError(t, err, "init")
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, "", res.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "default-src 'self'; report-uri /csp-report", res.Header().Get("Content-Security-Policy-Report-Only"))
+	})
+}
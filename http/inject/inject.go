@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/apex/up"
+	"github.com/apex/up/internal/csp"
 	"github.com/apex/up/internal/inject"
 )
 
@@ -16,6 +17,7 @@ import (
 type response struct {
 	http.ResponseWriter
 	rules  inject.Rules
+	nonce  string
 	body   bytes.Buffer
 	header bool
 	ignore bool
@@ -51,7 +53,7 @@ func (r *response) end() {
 		return
 	}
 
-	body := r.rules.Apply(r.body.String())
+	body := r.rules.ApplyNonce(r.body.String(), r.nonce)
 	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
 	io.WriteString(w, body)
 }
@@ -62,8 +64,15 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 		return next, nil
 	}
 
+	nonced := c.Security.CSP.Directives.Nonced()
+
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		res := &response{ResponseWriter: w, rules: c.Inject}
+
+		if nonced {
+			r, res.nonce = csp.WithNonce(r)
+		}
+
 		next.ServeHTTP(res, r)
 		res.end()
 	})
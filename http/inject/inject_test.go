@@ -5,12 +5,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/apex/up"
 	"github.com/tj/assert"
 
+	"github.com/apex/up/config"
 	"github.com/apex/up/http/errorpages"
+	"github.com/apex/up/http/headers"
 	"github.com/apex/up/http/static"
 	"github.com/apex/up/internal/inject"
 )
@@ -108,3 +111,47 @@ func TestInject(t *testing.T) {
 		assert.Equal(t, "<html><head>  <script src=\"/whatever.js\"></script>\n  </head><body></body></html>", res.Body.String())
 	})
 }
+
+func TestInject_nonce(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		Inject: inject.Rules{
+			"head": []*inject.Rule{
+				{
+					Type:  "inline script",
+					Value: "ready()",
+				},
+			},
+		},
+		Security: config.Security{
+			Preset: "strict",
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	s := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html><head></head><body></body></html>")
+	})
+
+	h, err := headers.New(c, s)
+	assert.NoError(t, err, "init")
+
+	h, err = New(c, h)
+	assert.NoError(t, err, "init")
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+
+	h.ServeHTTP(res, req)
+
+	policy := res.Header().Get("Content-Security-Policy")
+	i := strings.Index(policy, "'nonce-")
+	assert.NotEqual(t, -1, i, "nonce in policy")
+	nonce := policy[i+7 : i+7+24]
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, `<html><head>  <script nonce="`+nonce+`">ready()</script>`+"\n  </head><body></body></html>", res.Body.String())
+}
@@ -0,0 +1,240 @@
+// Package jwt provides JSON Web Token authentication,
+// forwarding verified claims to the application.
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/fanyang01/radix"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/jwks"
+	"github.com/apex/up/internal/logs"
+)
+
+// log context.
+var ctx = logs.Plugin("jwt")
+
+// ClaimPrefix is the header prefix of forwarded claims.
+const ClaimPrefix = "X-Up-Claim-"
+
+// invalid header name characters.
+var invalid = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// New jwt handler.
+func New(c *up.Config, next http.Handler) (http.Handler, error) {
+	conf := c.Auth.JWT
+	if conf == nil {
+		return next, nil
+	}
+
+	keys, err := keySet(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading keys")
+	}
+
+	rules := radix.NewPatternTrie()
+	for path, rule := range conf.Rules {
+		rules.Add(path, rule)
+	}
+
+	parser := &jwt.Parser{
+		ValidMethods: conf.Algorithms,
+	}
+
+	keyfunc := func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.Key(kid)
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		strip(r.Header)
+
+		v, ok := rules.Lookup(r.URL.Path)
+		if !ok || v.(*config.JWTRule).Disable {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rule := v.(*config.JWTRule)
+
+		s := bearer(r)
+		if s == "" {
+			unauthorized(w, "")
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := parser.ParseWithClaims(s, claims, keyfunc); err != nil {
+			ctx.WithError(err).Warn("invalid token")
+			unauthorized(w, "invalid_token")
+			return
+		}
+
+		if err := verify(conf, claims); err != nil {
+			ctx.WithError(err).Warn("invalid token")
+			unauthorized(w, "invalid_token")
+			return
+		}
+
+		if err := authorize(rule, claims); err != nil {
+			ctx.WithFields(log.Fields{
+				"path":  r.URL.Path,
+				"error": err.Error(),
+			}).Warn("forbidden")
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		forward(r.Header, claims)
+		next.ServeHTTP(w, r)
+	})
+
+	return h, nil
+}
+
+// keySet returns the key set for the config.
+func keySet(c *config.JWT) (*jwks.Set, error) {
+	if c.JWKSFile == "" {
+		return jwks.New(c.JWKSURL, c.Issuer), nil
+	}
+
+	keys, err := jwks.ReadFile(c.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwks.NewStatic(keys), nil
+}
+
+// verify the issuer and audience claims.
+func verify(c *config.JWT, claims jwt.MapClaims) error {
+	if c.Issuer != "" && !claims.VerifyIssuer(c.Issuer, true) {
+		return errors.New("invalid issuer")
+	}
+
+	if len(c.Audiences) == 0 {
+		return nil
+	}
+
+	aud := values(claims["aud"])
+	for _, a := range c.Audiences {
+		if contains(aud, a) {
+			return nil
+		}
+	}
+
+	return errors.New("invalid audience")
+}
+
+// authorize checks the scopes and claims required by the rule.
+func authorize(rule *config.JWTRule, claims jwt.MapClaims) error {
+	scope, _ := claims["scope"].(string)
+	scopes := strings.Fields(scope)
+	scopes = append(scopes, values(claims["scp"])...)
+
+	for _, s := range rule.Scopes {
+		if !contains(scopes, s) {
+			return errors.Errorf("missing scope %q", s)
+		}
+	}
+
+	for name, value := range rule.Claims {
+		if !contains(values(claims[name]), value) {
+			return errors.Errorf("claim %q mismatch", name)
+		}
+	}
+
+	return nil
+}
+
+// bearer returns the bearer token of the request.
+func bearer(r *http.Request) string {
+	s := r.Header.Get("Authorization")
+	if len(s) < 7 || !strings.EqualFold(s[:7], "bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(s[7:])
+}
+
+// unauthorized responds with a 401 and bearer challenge.
+func unauthorized(w http.ResponseWriter, code string) {
+	challenge := "Bearer"
+	if code != "" {
+		challenge += fmt.Sprintf(" error=%q", code)
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// strip removes client-supplied claim headers.
+func strip(h http.Header) {
+	for name := range h {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), ClaimPrefix) {
+			h.Del(name)
+		}
+	}
+}
+
+// forward sets the claims as request headers.
+func forward(h http.Header, claims jwt.MapClaims) {
+	for name, v := range claims {
+		h.Set(Header(name), value(v))
+	}
+}
+
+// Header returns the header name for a claim.
+func Header(name string) string {
+	name = strings.Trim(invalid.ReplaceAllString(name, "-"), "-")
+	return http.CanonicalHeaderKey(ClaimPrefix + name)
+}
+
+// value returns the header value for a claim.
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}:
+		return strings.Join(values(v), ",")
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// values returns a claim as a list of strings.
+func values(v interface{}) (list []string) {
+	switch v := v.(type) {
+	case nil:
+	case string:
+		list = append(list, v)
+	case []interface{}:
+		for _, s := range v {
+			list = append(list, value(s))
+		}
+	default:
+		list = append(list, value(v))
+	}
+	return
+}
+
+// contains returns true if s is present in list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+var app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Sub", r.Header.Get("X-Up-Claim-Sub"))
+	w.Header().Set("X-Roles", r.Header.Get("X-Up-Claim-Roles"))
+	w.Header().Set("X-Admin", r.Header.Get("X-Up-Claim-Admin"))
+	w.Write([]byte("Hello"))
+})
+
+// jwksServer returns a server for the given keys.
+func jwksServer(keys map[string]*rsa.PublicKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var set []map[string]string
+		for kid, k := range keys {
+			set = append(set, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": set})
+	}))
+}
+
+// sign returns a signed token.
+func sign(t testing.TB, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	assert.NoError(t, err, "signing")
+	return s
+}
+
+func request(h http.Handler, path, token string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("X-Up-Claim-Admin", "true")
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err, "generate")
+
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err, "generate")
+
+	s := jwksServer(map[string]*rsa.PublicKey{"a": &key.PublicKey})
+	defer s.Close()
+
+	c := &up.Config{
+		Auth: config.Auth{
+			JWT: &config.JWT{
+				Issuer:    "https://auth.example.com/",
+				Audiences: []string{"api"},
+				JWKSURL:   s.URL,
+				Rules: map[string]*config.JWTRule{
+					"/*":       {},
+					"/health":  {Disable: true},
+					"/admin/*": {Scopes: []string{"admin"}},
+					"/pets/*":  {Claims: map[string]string{"roles": "vet"}},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Auth.Default(), "default")
+	assert.NoError(t, c.Auth.Validate(), "validate")
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://auth.example.com/",
+			"aud":   []string{"web", "api"},
+			"sub":   "tobi",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "read write",
+			"roles": []string{"owner", "vet"},
+		}
+	}
+
+	t.Run("disabled path", func(t *testing.T) {
+		res := request(h, "/health", "")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "", res.Header().Get("X-Admin"))
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		res := request(h, "/", "")
+		assert.Equal(t, 401, res.Code)
+		assert.Equal(t, "Bearer", res.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		res := request(h, "/", sign(t, key, "a", claims()))
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "tobi", res.Header().Get("X-Sub"))
+		assert.Equal(t, "owner,vet", res.Header().Get("X-Roles"))
+		assert.Equal(t, "", res.Header().Get("X-Admin"))
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		res := request(h, "/", sign(t, other, "a", claims()))
+		assert.Equal(t, 401, res.Code)
+		assert.Equal(t, `Bearer error="invalid_token"`, res.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		res := request(h, "/", sign(t, other, "b", claims()))
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("disallowed algorithm", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		s, err := token.SignedString([]byte("secret"))
+		assert.NoError(t, err, "signing")
+		res := request(h, "/", s)
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		cl := claims()
+		cl["exp"] = time.Now().Add(-time.Minute).Unix()
+		res := request(h, "/", sign(t, key, "a", cl))
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("invalid issuer", func(t *testing.T) {
+		cl := claims()
+		cl["iss"] = "https://evil.example.com/"
+		res := request(h, "/", sign(t, key, "a", cl))
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("invalid audience", func(t *testing.T) {
+		cl := claims()
+		cl["aud"] = "web"
+		res := request(h, "/", sign(t, key, "a", cl))
+		assert.Equal(t, 401, res.Code)
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		res := request(h, "/admin/users", sign(t, key, "a", claims()))
+		assert.Equal(t, 403, res.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope"`, res.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		cl := claims()
+		cl["scope"] = "read admin"
+		res := request(h, "/admin/users", sign(t, key, "a", cl))
+		assert.Equal(t, 200, res.Code)
+	})
+
+	t.Run("scp", func(t *testing.T) {
+		cl := claims()
+		cl["scp"] = []string{"admin"}
+		res := request(h, "/admin/users", sign(t, key, "a", cl))
+		assert.Equal(t, 200, res.Code)
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		res := request(h, "/pets/tobi", sign(t, key, "a", claims()))
+		assert.Equal(t, 200, res.Code)
+
+		cl := claims()
+		cl["roles"] = "owner"
+		res = request(h, "/pets/tobi", sign(t, key, "a", cl))
+		assert.Equal(t, 403, res.Code)
+	})
+}
+
+func TestJWT_disabled(t *testing.T) {
+	c := &up.Config{}
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	res := request(h, "/", "")
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestHeader(t *testing.T) {
+	assert.Equal(t, "X-Up-Claim-Sub", Header("sub"))
+	assert.Equal(t, "X-Up-Claim-Email-Verified", Header("email_verified"))
+	assert.Equal(t, "X-Up-Claim-Https-Example-Com-Roles", Header("https://example.com/roles"))
+}
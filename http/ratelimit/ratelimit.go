@@ -0,0 +1,79 @@
+// Package ratelimit provides per-client rate limiting.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/ratelimit"
+)
+
+// log context.
+var ctx = logs.Plugin("ratelimit")
+
+// New rate limiting handler using an in-memory store.
+func New(c *up.Config, next http.Handler) (http.Handler, error) {
+	return NewWithStore(c, ratelimit.NewMemoryStore(), next)
+}
+
+// NewWithStore returns a new rate limiting handler using the given store.
+func NewWithStore(c *up.Config, store ratelimit.Store, next http.Handler) (http.Handler, error) {
+	if len(c.RateLimit) == 0 {
+		return next, nil
+	}
+
+	rules, err := ratelimit.Compile(c.RateLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling rules")
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, rule := rules.Lookup(r.URL.Path)
+		if rule == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		l := rule.Limit()
+		key := rule.ClientKey(r)
+
+		res, err := store.Take(path+" "+key, l)
+		if err != nil {
+			ctx.WithError(err).Error("taking token")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(l.Burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", ceil(res.Reset))
+
+		if !res.Allowed {
+			ctx.WithFields(log.Fields{
+				"path": path,
+				"key":  key,
+			}).Warn("rate limited")
+
+			header.Set("Retry-After", ceil(res.RetryAfter))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return h, nil
+}
+
+// ceil returns the duration in seconds, rounded up.
+func ceil(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
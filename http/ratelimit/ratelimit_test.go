@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	c := &up.Config{
+		RateLimit: ratelimit.Rules{
+			"/api/*": {
+				Key:      "header:X-Api-Key",
+				Requests: 2,
+			},
+		},
+	}
+
+	assert.NoError(t, c.RateLimit.Default(), "default")
+	assert.NoError(t, c.RateLimit.Validate(), "validate")
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello"))
+	})
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Api-Key", key)
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		res := request("/api/pets", "tobi")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Hello", res.Body.String())
+		assert.Equal(t, "2", res.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", res.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", res.Header().Get("RateLimit-Reset"))
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		res := request("/api/pets", "tobi")
+		assert.Equal(t, 200, res.Code)
+
+		res = request("/api/pets", "tobi")
+		assert.Equal(t, 429, res.Code)
+		assert.Equal(t, "Too Many Requests\n", res.Body.String())
+		assert.Equal(t, "30", res.Header().Get("Retry-After"))
+		assert.Equal(t, "0", res.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("other client", func(t *testing.T) {
+		res := request("/api/pets", "loki")
+		assert.Equal(t, 200, res.Code)
+	})
+
+	t.Run("unmatched", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			res := request("/", "tobi")
+			assert.Equal(t, 200, res.Code)
+			assert.Equal(t, "", res.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
+
+func TestRateLimit_disabled(t *testing.T) {
+	c := &up.Config{}
+
+	h, err := New(c, http.NotFoundHandler())
+	assert.NoError(t, err, "init")
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Code)
+	assert.Equal(t, "", res.Header().Get("RateLimit-Limit"))
+}
@@ -4,6 +4,7 @@ package redirects
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/apex/up"
@@ -62,7 +63,7 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	}
 
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rule := rules.Lookup(r.URL.Path)
+		rule := rules.Lookup(r)
 
 		ctx := ctx.WithFields(log.Fields{
 			"path": r.URL.Path,
@@ -75,14 +76,13 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 			return
 		}
 
-		// destination path
-		path := rule.URL(r.URL.Path)
+		// destination
+		dest := rule.Destination(r)
 
 		// forced rewrite
 		if rule.IsRewrite() && rule.Force {
-			ctx.WithField("dest", path).Info("forced rewrite")
-			r.Header.Set("X-Original-Path", r.URL.Path)
-			r.URL.Path = path
+			ctx.WithField("dest", dest).Info("forced rewrite")
+			rewriteURL(r, dest)
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -93,9 +93,8 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 			next.ServeHTTP(res, r)
 
 			if res.isNotFound {
-				ctx.WithField("dest", path).Info("rewrite")
-				r.Header.Set("X-Original-Path", r.URL.Path)
-				r.URL.Path = path
+				ctx.WithField("dest", dest).Info("rewrite")
+				rewriteURL(r, dest)
 				// This hack is necessary for SPAs because the Go
 				// static file server uses .html to set the correct mime,
 				// ideally it uses the file's extension or magic number etc.
@@ -106,8 +105,8 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 		}
 
 		// redirect
-		ctx.WithField("dest", path).Info("redirect")
-		w.Header().Set("Location", path)
+		ctx.WithField("dest", dest).Info("redirect")
+		w.Header().Set("Location", dest)
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(rule.Status)
 		fmt.Fprintln(w, http.StatusText(rule.Status))
@@ -115,3 +114,17 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 
 	return h, nil
 }
+
+// rewriteURL rewrites the request path, and the query
+// string when present in the destination.
+func rewriteURL(r *http.Request, dest string) {
+	r.Header.Set("X-Original-Path", r.URL.Path)
+
+	if i := strings.Index(dest, "?"); i != -1 {
+		r.URL.Path = dest[:i]
+		r.URL.RawQuery = dest[i+1:]
+		return
+	}
+
+	r.URL.Path = dest
+}
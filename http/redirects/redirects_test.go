@@ -14,42 +14,72 @@ import (
 	"github.com/apex/up/internal/redirect"
 )
 
+var app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprintln(w, "Index")
+	case r.URL.Path == "/api/v1":
+		fmt.Fprintln(w, "API V1")
+	case r.URL.Path == "/products":
+		fmt.Fprintln(w, "products")
+	case strings.Contains(r.URL.Path, "/docs"):
+		fmt.Fprintf(w, "docs %s", r.URL.Path)
+	case strings.HasPrefix(r.URL.Path, "/brand"):
+		fmt.Fprintf(w, "shop %s", r.URL.Path)
+	case strings.HasPrefix(r.URL.Path, "/setting"):
+		fmt.Fprintf(w, "settings %s", r.URL.Path)
+	case strings.HasPrefix(r.URL.Path, "/admin"):
+		fmt.Fprintf(w, "admin %s", r.URL.Path)
+	default:
+		http.NotFound(w, r)
+	}
+})
+
 func TestRedirects(t *testing.T) {
 	t.Run("from config", func(t *testing.T) {
 		c := &up.Config{
 			Redirects: redirect.Rules{
-				"/blog": {
+				{
+					Path:     "/blog",
 					Location: "https://blog.apex.sh",
 					Status:   301,
 				},
-				"/enterprise": {
+				{
+					Path:     "/enterprise",
 					Location: "/docs/enterprise",
 					Status:   302,
 				},
-				"/api": {
+				{
+					Path:     "/api",
 					Location: "/api/v1",
 					Status:   200,
 				},
-				"/products": {
+				{
+					Path:     "/products",
 					Location: "/store",
 					Status:   301,
 				},
-				"/app/*": {
-					Location: "/",
-				},
-				"/app/login": {
+				{
+					Path:     "/app/login",
 					Location: "https://app.apex.sh",
 					Status:   301,
 				},
-				"/documentation/:product/guides/:guide": {
+				{
+					Path:     "/app/*",
+					Location: "/",
+				},
+				{
+					Path:     "/documentation/:product/guides/:guide",
 					Location: "/docs/:product/:guide",
 					Status:   200,
 				},
-				"/shop/:brand": {
+				{
+					Path:     "/shop/:brand",
 					Location: "/products/:brand",
 					Status:   301,
 				},
-				"/settings/*": {
+				{
+					Path:     "/settings/*",
 					Location: "/admin/:splat",
 					Status:   200,
 					Force:    true,
@@ -57,34 +87,98 @@ func TestRedirects(t *testing.T) {
 			},
 		}
 
-		handle := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch {
-			case r.URL.Path == "/":
-				fmt.Fprintln(w, "Index")
-			case r.URL.Path == "/api/v1":
-				fmt.Fprintln(w, "API V1")
-			case r.URL.Path == "/products":
-				fmt.Fprintln(w, "products")
-			case strings.Contains(r.URL.Path, "/docs"):
-				fmt.Fprintf(w, "docs %s", r.URL.Path)
-			case strings.HasPrefix(r.URL.Path, "/brand"):
-				fmt.Fprintf(w, "shop %s", r.URL.Path)
-			case strings.HasPrefix(r.URL.Path, "/setting"):
-				fmt.Fprintf(w, "settings %s", r.URL.Path)
-			case strings.HasPrefix(r.URL.Path, "/admin"):
-				fmt.Fprintf(w, "admin %s", r.URL.Path)
-			default:
-				http.NotFound(w, r)
+		h, err := New(c, app)
+		assert.NoError(t, err, "init")
+
+		test(t, h)
+	})
+
+	t.Run("from json", func(t *testing.T) {
+		c, err := up.ParseConfigString(`{
+			"name": "app",
+			"type": "static",
+			"redirects": {
+				"/blog": { "location": "https://blog.apex.sh", "status": 301 },
+				"/enterprise": { "location": "/docs/enterprise", "status": 302 },
+				"/api": { "location": "/api/v1", "status": 200 },
+				"/products": { "location": "/store", "status": 301 },
+				"/app/*": { "location": "/" },
+				"/app/login": { "location": "https://app.apex.sh", "status": 301 },
+				"/documentation/:product/guides/:guide": { "location": "/docs/:product/:guide", "status": 200 },
+				"/shop/:brand": { "location": "/products/:brand", "status": 301 },
+				"/settings/*": { "location": "/admin/:splat", "status": 200, "force": true }
 			}
-		})
+		}`)
+		assert.NoError(t, err, "config")
 
-		h, err := New(c, handle)
+		h, err := New(c, app)
 		assert.NoError(t, err, "init")
 
 		test(t, h)
 	})
 }
 
+func TestRedirects_conditions(t *testing.T) {
+	c := &up.Config{
+		Redirects: redirect.Rules{
+			{
+				Path:     "/*",
+				Host:     "www.apex.sh",
+				Location: "https://apex.sh/:splat",
+				Status:   301,
+			},
+			{
+				Path:             "/*",
+				Query:            map[string]string{"ref": "*"},
+				Location:         "/:splat",
+				Status:           301,
+				QueryPassthrough: true,
+			},
+			{
+				Path:             "/v1/*",
+				Location:         "/api/v1?legacy=1",
+				Force:            true,
+				QueryPassthrough: true,
+			},
+		},
+	}
+
+	h, err := New(c, app)
+	assert.NoError(t, err, "init")
+
+	t.Run("host", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "http://www.apex.sh/blog?page=2", nil)
+
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "https://apex.sh/blog", res.Header().Get("Location"))
+	})
+
+	t.Run("query cleanup", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/blog?ref=hn&page=2", nil)
+
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "/blog?page=2", res.Header().Get("Location"))
+	})
+
+	t.Run("rewrite query", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/v1/pets?limit=5", nil)
+
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "/api/v1", req.URL.Path)
+		assert.Equal(t, "legacy=1&limit=5", req.URL.RawQuery)
+		assert.Equal(t, "API V1\n", res.Body.String())
+	})
+}
+
 func test(t *testing.T, h http.Handler) {
 	os.Chdir("testdata")
 	defer os.Chdir("..")
@@ -0,0 +1,57 @@
+package static
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/apex/up"
+)
+
+// mount is a static handler at a path prefix.
+type mount struct {
+	*Handler
+	exclusive bool
+}
+
+// NewMounts returns a handler serving the static mounts of the config,
+// relaying to next for other paths and files which do not exist.
+func NewMounts(c *up.Config, next http.Handler) http.Handler {
+	var mounts []mount
+
+	for _, m := range c.Static.ServerMounts() {
+		h := NewDir(c, m.Dir, m.Prefix)
+		h.spa = h.spa && m.Exclusive
+		mounts = append(mounts, mount{Handler: h, exclusive: m.Exclusive})
+	}
+
+	if len(mounts) == 0 {
+		return next
+	}
+
+	// most specific prefixes first
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return len(mounts[i].prefix) > len(mounts[j].prefix)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, m := range mounts {
+			if !strings.HasPrefix(r.URL.Path+"/", m.prefix) {
+				continue
+			}
+
+			if m.Serve(w, r) {
+				return
+			}
+
+			if m.exclusive {
+				http.NotFound(w, r)
+				return
+			}
+
+			break
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
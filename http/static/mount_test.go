@@ -0,0 +1,96 @@
+package static
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+)
+
+var app = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("app " + r.URL.Path))
+})
+
+func TestNewMounts(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		Static: config.Static{
+			Dir:         "testdata",
+			Prefix:      "/public",
+			SPAFallback: true,
+			Mounts: []*config.StaticMount{
+				{Prefix: "/assets", Dir: "testdata", Exclusive: true},
+				{Prefix: "/public/blog", Dir: "testdata/blog"},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	h := NewMounts(c, app)
+
+	t.Run("file", func(t *testing.T) {
+		res := serve(h, "/public/style.css", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "body { background: whatever }\n", res.Body.String())
+	})
+
+	t.Run("directory index", func(t *testing.T) {
+		res := serve(h, "/public/", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Index HTML\n", res.Body.String())
+
+		res = serve(h, "/public", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Index HTML\n", res.Body.String())
+
+		res = serve(h, "/public/docs", nil)
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "/public/docs/", res.Header().Get("Location"))
+	})
+
+	t.Run("most specific mount", func(t *testing.T) {
+		res := serve(h, "/public/blog/", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Blog HTML\n", res.Body.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		res := serve(h, "/public/users/tobi", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "app /public/users/tobi", res.Body.String())
+	})
+
+	t.Run("exclusive mount", func(t *testing.T) {
+		res := serve(h, "/assets/style.css", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "body { background: whatever }\n", res.Body.String())
+
+		res = serve(h, "/assets/missing.css", nil)
+		assert.Equal(t, 404, res.Code)
+
+		res = serve(h, "/assets/users", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Index HTML\n", res.Body.String())
+	})
+
+	t.Run("other paths", func(t *testing.T) {
+		res := serve(h, "/style.css", nil)
+		assert.Equal(t, "app /style.css", res.Body.String())
+
+		res = serve(h, "/publications", nil)
+		assert.Equal(t, "app /publications", res.Body.String())
+	})
+}
+
+func TestNewMounts_none(t *testing.T) {
+	c := &up.Config{Name: "app"}
+	assert.NoError(t, c.Default(), "default")
+
+	res := serve(NewMounts(c, app), "/index.html", nil)
+	assert.Equal(t, "app /index.html", res.Body.String())
+}
@@ -2,12 +2,264 @@
 package static
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"mime"
 	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/http/compress"
 )
 
+// extensions of precompressed files by encoding.
+var extensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// hashed matches fingerprinted file names such as app.3f2a9c1b.js.
+var hashed = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// immutable cache control for fingerprinted files.
+const immutable = "public, max-age=31536000, immutable"
+
+// etag is a cached entity tag.
+type etag struct {
+	size  int64
+	mod   time.Time
+	value string
+}
+
+// Handler serves static files.
+type Handler struct {
+	dir        http.Dir
+	prefix     string
+	config     *config.Static
+	algorithms []string
+	spa        bool
+
+	mu    sync.Mutex
+	etags map[string]etag
+}
+
 // New static handler.
 func New(c *up.Config) http.Handler {
-	return http.FileServer(http.Dir(c.Static.Dir))
+	return NewDir(c, c.Static.Dir, "/")
+}
+
+// NewDir returns a static handler serving dir at the given path prefix.
+func NewDir(c *up.Config, dir, prefix string) *Handler {
+	h := &Handler{
+		dir:    http.Dir(dir),
+		prefix: prefix,
+		config: &c.Static,
+		spa:    c.Static.SPAFallback,
+		etags:  make(map[string]etag),
+	}
+
+	if !c.Compression.Disable {
+		h.algorithms = c.Compression.Algorithms
+	}
+
+	return h
+}
+
+// ServeHTTP implementation.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.Serve(w, r) {
+		http.NotFound(w, r)
+	}
+}
+
+// Serve the file for the request, returning false if it does not exist.
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) bool {
+	upath := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(h.prefix, "/"))
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+
+	name := path.Clean(upath)
+
+	// redirect to canonical paths
+	if strings.HasSuffix(name, "/index.html") && h.exists(name) {
+		h.redirect(w, r, strings.TrimSuffix(name, "index.html"))
+		return true
+	}
+
+	if h.config.CleanURLs && path.Ext(name) == ".html" && h.exists(name) {
+		h.redirect(w, r, strings.TrimSuffix(name, ".html"))
+		return true
+	}
+
+	f, info, err := h.open(name)
+
+	// directories
+	if err == nil && info.IsDir() {
+		f.Close()
+
+		if !strings.HasSuffix(upath, "/") {
+			h.redirect(w, r, name+"/")
+			return true
+		}
+
+		name = path.Join(name, "index.html")
+		f, info, err = h.open(name)
+
+		if err != nil && h.config.DirectoryListing {
+			http.StripPrefix(strings.TrimSuffix(h.prefix, "/"), http.FileServer(h.dir)).ServeHTTP(w, r)
+			return true
+		}
+	}
+
+	// clean urls
+	if err != nil && h.config.CleanURLs && path.Ext(name) == "" && name != "/" {
+		name += ".html"
+		f, info, err = h.open(name)
+	}
+
+	// single-page app fallback
+	if err != nil && h.spa && path.Ext(name) == "" {
+		name = "/index.html"
+		f, info, err = h.open(name)
+	}
+
+	if err != nil {
+		return false
+	}
+
+	defer f.Close()
+	h.serve(w, r, name, f, info)
+	return true
+}
+
+// serve the file, or its precompressed sibling.
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, name string, f http.File, info os.FileInfo) {
+	header := w.Header()
+
+	if kind := mime.TypeByExtension(path.Ext(name)); kind != "" {
+		accept := r.Header.Get("Accept-Encoding")
+
+		// the Vary header field is set by the compression middleware
+		if encoding := compress.Negotiate(accept, h.precompressed(name)); encoding != "" {
+			if cf, cinfo, err := h.open(name + extensions[encoding]); err == nil {
+				defer cf.Close()
+				f, info = cf, cinfo
+				header.Set("Content-Type", kind)
+				header.Set("Content-Encoding", encoding)
+			}
+		}
+	}
+
+	if tag, err := h.etag(name, f, info); err == nil {
+		header.Set("ETag", tag)
+	}
+
+	if hashed.MatchString(name) {
+		header.Set("Cache-Control", immutable)
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// open returns the file and its info, returning
+// an error satisfying os.IsNotExist when missing.
+func (h *Handler) open(name string) (http.File, os.FileInfo, error) {
+	f, err := h.dir.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+// exists returns true if the file exists.
+func (h *Handler) exists(name string) bool {
+	f, _, err := h.open(name)
+	if err != nil {
+		return false
+	}
+
+	f.Close()
+	return true
+}
+
+// precompressed returns the encodings of the precompressed
+// siblings of the file name, in order of preference.
+func (h *Handler) precompressed(name string) (encodings []string) {
+	for _, encoding := range h.algorithms {
+		f, info, err := h.open(name + extensions[encoding])
+		if err != nil {
+			continue
+		}
+
+		f.Close()
+
+		if !info.IsDir() {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return
+}
+
+// etag returns a strong entity tag derived from the file's contents,
+// cached until the file's size or modification time changes.
+func (h *Handler) etag(name string, f http.File, info os.FileInfo) (string, error) {
+	key := name + "|" + info.Name()
+
+	h.mu.Lock()
+	e, ok := h.etags[key]
+	h.mu.Unlock()
+
+	if ok && e.size == info.Size() && e.mod.Equal(info.ModTime()) {
+		return e.value, nil
+	}
+
+	hash := sha1.New()
+
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	e = etag{
+		size:  info.Size(),
+		mod:   info.ModTime(),
+		value: `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	}
+
+	h.mu.Lock()
+	h.etags[key] = e
+	h.mu.Unlock()
+
+	return e.value, nil
+}
+
+// redirect to the given path relative to the
+// prefix, preserving the query string.
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request, path string) {
+	path = strings.TrimSuffix(h.prefix, "/") + path
+
+	if q := r.URL.RawQuery; q != "" {
+		path += "?" + q
+	}
+
+	w.Header().Set("Location", path)
+	w.WriteHeader(http.StatusMovedPermanently)
 }
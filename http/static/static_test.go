@@ -1,8 +1,12 @@
 package static
 
 import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/apex/up"
@@ -58,6 +62,25 @@ func test(t *testing.T, c *up.Config) {
 		assert.Equal(t, "body { background: whatever }\n", res.Body.String())
 	})
 
+	t.Run("precompressed", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/style.css", nil)
+		req.Header.Set("Accept-Encoding", "br, gzip")
+
+		h.ServeHTTP(res, req)
+
+		gz, err := gzip.NewReader(res.Body)
+		assert.NoError(t, err, "reader")
+
+		b, err := ioutil.ReadAll(gz)
+		assert.NoError(t, err, "reading")
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+		assert.Equal(t, "text/css; charset=utf-8", res.Header().Get("Content-Type"))
+		assert.Equal(t, "body { background: whatever }\n", string(b))
+	})
+
 	t.Run("missing", func(t *testing.T) {
 		res := httptest.NewRecorder()
 		req := httptest.NewRequest("GET", "/notfound", nil)
@@ -79,3 +102,110 @@ func test(t *testing.T, c *up.Config) {
 		assert.Equal(t, "", res.Body.String())
 	})
 }
+
+func serve(h http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestStatic_options(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		Static: config.Static{
+			Dir: "testdata",
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	t.Run("directory index", func(t *testing.T) {
+		h := New(c)
+
+		res := serve(h, "/blog/", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Blog HTML\n", res.Body.String())
+
+		res = serve(h, "/blog?page=2", nil)
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "/blog/?page=2", res.Header().Get("Location"))
+
+		res = serve(h, "/blog/index.html", nil)
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "/blog/", res.Header().Get("Location"))
+	})
+
+	t.Run("directory listing disabled", func(t *testing.T) {
+		res := serve(New(c), "/docs/", nil)
+		assert.Equal(t, 404, res.Code)
+	})
+
+	t.Run("directory listing", func(t *testing.T) {
+		c.Static.DirectoryListing = true
+		defer func() { c.Static.DirectoryListing = false }()
+
+		res := serve(New(c), "/docs/", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Contains(t, res.Body.String(), "guide.txt")
+	})
+
+	t.Run("clean urls", func(t *testing.T) {
+		c.Static.CleanURLs = true
+		defer func() { c.Static.CleanURLs = false }()
+		h := New(c)
+
+		res := serve(h, "/about", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "text/html; charset=utf-8", res.Header().Get("Content-Type"))
+		assert.Equal(t, "About HTML\n", res.Body.String())
+
+		res = serve(h, "/about.html", nil)
+		assert.Equal(t, 301, res.Code)
+		assert.Equal(t, "/about", res.Header().Get("Location"))
+
+		res = serve(h, "/contact", nil)
+		assert.Equal(t, 404, res.Code)
+	})
+
+	t.Run("spa fallback", func(t *testing.T) {
+		c.Static.SPAFallback = true
+		defer func() { c.Static.SPAFallback = false }()
+		h := New(c)
+
+		res := serve(h, "/users/tobi", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Index HTML\n", res.Body.String())
+
+		res = serve(h, "/missing.js", nil)
+		assert.Equal(t, 404, res.Code)
+	})
+
+	t.Run("strong etags", func(t *testing.T) {
+		h := New(c)
+
+		res := serve(h, "/style.css", nil)
+		etag := res.Header().Get("ETag")
+		assert.Len(t, etag, 42)
+		assert.False(t, strings.HasPrefix(etag, "W/"))
+		assert.Equal(t, "", res.Header().Get("Cache-Control"))
+
+		res = serve(h, "/style.css", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, 304, res.Code)
+
+		res = serve(h, "/style.css", map[string]string{"Accept-Encoding": "gzip"})
+		assert.NotEqual(t, etag, res.Header().Get("ETag"))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		res := serve(New(c), "/app.0123abcd.js", nil)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "public, max-age=31536000, immutable", res.Header().Get("Cache-Control"))
+	})
+}
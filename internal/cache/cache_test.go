@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(10)
+
+	assert.True(t, c.Set("a", "A", 4))
+	assert.True(t, c.Set("b", "B", 4))
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "A", v)
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		assert.True(t, c.Set("c", "C", 4))
+		_, ok := c.Get("b")
+		assert.False(t, ok)
+		assert.Equal(t, 2, c.Len())
+		assert.Equal(t, int64(8), c.Size())
+	})
+
+	t.Run("replaces existing values", func(t *testing.T) {
+		assert.True(t, c.Set("a", "AA", 6))
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "AA", v)
+		assert.Equal(t, int64(10), c.Size())
+	})
+
+	t.Run("rejects values larger than the cache", func(t *testing.T) {
+		assert.False(t, c.Set("d", "D", 11))
+		_, ok := c.Get("d")
+		assert.False(t, ok)
+	})
+
+	t.Run("removes values", func(t *testing.T) {
+		c.Remove("a")
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, int64(4), c.Size())
+	})
+}
+
+func TestParseControl(t *testing.T) {
+	c := ParseControl(`public, max-age=60, s-maxage="120", stale-while-revalidate=30, bogus`)
+	assert.True(t, c.Public)
+	assert.False(t, c.Private)
+	assert.Equal(t, 60*time.Second, *c.MaxAge)
+	assert.Equal(t, 120*time.Second, *c.SMaxAge)
+	assert.Equal(t, 30*time.Second, c.StaleWhileRevalidate)
+	assert.Equal(t, 120*time.Second, c.TTL(time.Minute))
+
+	c = ParseControl("private, no-store, No-Cache")
+	assert.True(t, c.Private)
+	assert.True(t, c.NoStore)
+	assert.True(t, c.NoCache)
+	assert.Equal(t, time.Minute, c.TTL(time.Minute))
+
+	c = ParseControl("max-age=0")
+	assert.Equal(t, time.Duration(0), c.TTL(time.Minute))
+
+	c = ParseControl("max-age=-5")
+	assert.Nil(t, c.MaxAge)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Control is a parsed Cache-Control header field.
+type Control struct {
+	Public               bool
+	Private              bool
+	NoCache              bool
+	NoStore              bool
+	MaxAge               *time.Duration
+	SMaxAge              *time.Duration
+	StaleWhileRevalidate time.Duration
+}
+
+// TTL returns the shared cache freshness lifetime, preferring
+// s-maxage to max-age, and falling back on the given default.
+func (c *Control) TTL(fallback time.Duration) time.Duration {
+	switch {
+	case c.SMaxAge != nil:
+		return *c.SMaxAge
+	case c.MaxAge != nil:
+		return *c.MaxAge
+	default:
+		return fallback
+	}
+}
+
+// ParseControl parses a Cache-Control header field.
+// Unknown and malformed directives are ignored.
+func ParseControl(s string) *Control {
+	c := &Control{}
+
+	for _, d := range strings.Split(s, ",") {
+		name, value := d, ""
+		if i := strings.Index(d, "="); i != -1 {
+			name, value = d[:i], strings.Trim(strings.TrimSpace(d[i+1:]), `"`)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "public":
+			c.Public = true
+		case "private":
+			c.Private = true
+		case "no-cache":
+			c.NoCache = true
+		case "no-store":
+			c.NoStore = true
+		case "max-age":
+			c.MaxAge = seconds(value)
+		case "s-maxage":
+			c.SMaxAge = seconds(value)
+		case "stale-while-revalidate":
+			if d := seconds(value); d != nil {
+				c.StaleWhileRevalidate = *d
+			}
+		}
+	}
+
+	return c
+}
+
+// seconds parses a delta-seconds value.
+func seconds(s string) *time.Duration {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return nil
+	}
+
+	d := time.Duration(n) * time.Second
+	return &d
+}
@@ -0,0 +1,102 @@
+// Package cache provides a size-bounded LRU
+// and Cache-Control header field parsing.
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// item is a cached value.
+type item struct {
+	key   string
+	value interface{}
+	size  int64
+}
+
+// LRU is a least-recently-used cache bounded by the size of its values.
+type LRU struct {
+	mu    sync.Mutex
+	max   int64
+	size  int64
+	list  *list.List
+	items map[string]*list.Element
+}
+
+// NewLRU returns a new LRU with the given maximum size in bytes.
+func NewLRU(max int64) *LRU {
+	return &LRU{
+		max:   max,
+		list:  list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value of key, marking it as recently used.
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.list.MoveToFront(e)
+	return e.Value.(*item).value, true
+}
+
+// Set the value of key, evicting the least recently used values
+// to make room. Values larger than the cache are not stored.
+func (c *LRU) Set(key string, value interface{}, size int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+
+	if size > c.max {
+		return false
+	}
+
+	for c.size+size > c.max {
+		c.remove(c.list.Back())
+	}
+
+	c.items[key] = c.list.PushFront(&item{key, value, size})
+	c.size += size
+	return true
+}
+
+// Remove the value of key.
+func (c *LRU) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Len returns the number of values.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list.Len()
+}
+
+// Size returns the total size of values.
+func (c *LRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// remove element e.
+func (c *LRU) remove(e *list.Element) {
+	i := e.Value.(*item)
+	c.list.Remove(e)
+	delete(c.items, i.key)
+	c.size -= i.size
+}
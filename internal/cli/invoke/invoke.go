@@ -0,0 +1,128 @@
+package invoke
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tj/kingpin"
+
+	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/colors"
+	"github.com/apex/up/internal/proxy"
+	"github.com/apex/up/internal/stats"
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
+)
+
+func init() {
+	cmd := root.Command("invoke", "Invoke a stage directly.")
+	cmd.Example(`up invoke GET /`, "Request the staging root path.")
+	cmd.Example(`up invoke production GET /admin -H 'Authorization: Bearer token'`, "Request a production path with a header field.")
+	cmd.Example(`up invoke POST /pets -d '{ "name": "Tobi" }'`, "Request a staging path with a body.")
+
+	args := cmd.Arg("args", "Optional stage name, request method and path.").Required().Strings()
+	headers := cmd.Flag("header", "Request header field.").Short('H').Strings()
+	data := cmd.Flag("data", "Request body.").Short('d').String()
+
+	cmd.Action(func(_ *kingpin.ParseContext) error {
+		c, p, err := root.Init()
+		if err != nil {
+			return errors.Wrap(err, "initializing")
+		}
+
+		stage := "staging"
+		a := *args
+
+		switch len(a) {
+		case 2:
+		case 3:
+			stage, a = a[0], a[1:]
+		default:
+			return errors.New("expected [stage] METHOD PATH")
+		}
+
+		method, path := strings.ToUpper(a[0]), a[1]
+		region := c.Regions[0]
+
+		stats.Track("Invoke", map[string]interface{}{
+			"region":  region,
+			"stage":   stage,
+			"method":  method,
+			"headers": len(*headers),
+			"data":    *data != "",
+		})
+
+		if err := validate.List(stage, c.Stages.RemoteNames()); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(method, path, strings.NewReader(*data))
+		if err != nil {
+			return errors.Wrap(err, "creating request")
+		}
+
+		for _, h := range *headers {
+			parts := strings.SplitN(h, ":", 2)
+			if len(parts) != 2 {
+				return errors.Errorf("invalid header field %q", h)
+			}
+			req.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		}
+
+		in, err := proxy.NewInput(req)
+		if err != nil {
+			return errors.Wrap(err, "creating input")
+		}
+		in.RequestContext.Stage = stage
+
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errors.Wrap(err, "marshaling")
+		}
+
+		res, err := p.Invoke(region, stage, b)
+		if err != nil {
+			return err
+		}
+
+		var out proxy.Output
+		if err := json.Unmarshal(res.Payload, &out); err != nil {
+			return errors.Wrap(err, "parsing response")
+		}
+
+		body := []byte(out.Body)
+		if out.IsBase64Encoded {
+			body, err = base64.StdEncoding.DecodeString(out.Body)
+			if err != nil {
+				return errors.Wrap(err, "decoding body")
+			}
+		}
+
+		defer util.Pad()()
+
+		util.LogName("status", "%d %s", out.StatusCode, http.StatusText(out.StatusCode))
+
+		var names []string
+		for name := range out.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("     %s %s\n", colors.Gray(name+":"), out.Headers[name])
+		}
+
+		fmt.Printf("\n%s\n", body)
+
+		if res.Logs != "" {
+			fmt.Printf("\n%s\n", colors.Gray(util.PrefixLines(strings.TrimSpace(res.Logs), "     ")))
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/apex/go-apex"
+	"github.com/pkg/errors"
+	"github.com/tj/kingpin"
+
+	"github.com/apex/up"
+	"github.com/apex/up/handler"
+	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/colors"
+	"github.com/apex/up/internal/proxy"
+	"github.com/apex/up/internal/record"
+	"github.com/apex/up/internal/stats"
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
+)
+
+func init() {
+	cmd := root.Command("replay", "Replay recorded requests.")
+	cmd.Example(`up replay events.json`, "Replay requests against the development server.")
+	cmd.Example(`up replay events.json --stage staging`, "Replay requests against the staging stage.")
+
+	file := cmd.Arg("file", "Recorded events file.").Required().String()
+	stage := cmd.Flag("stage", "Target stage name.").Short('s').Default("development").String()
+
+	cmd.Action(func(_ *kingpin.ParseContext) error {
+		defer util.Pad()()
+
+		c, p, err := root.Init()
+		if err != nil {
+			return errors.Wrap(err, "initializing")
+		}
+
+		events, err := record.ReadFile(*file)
+		if err != nil {
+			return errors.Wrap(err, "reading events")
+		}
+
+		stats.Track("Replay", map[string]interface{}{
+			"stage":  *stage,
+			"events": len(events),
+		})
+
+		var h apex.Handler
+		if *stage == "development" {
+			h, err = local(c, p)
+		} else {
+			h, err = remote(c, p, *stage)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		for _, e := range events {
+			if !replay(h, e) {
+				failed++
+			}
+		}
+
+		fmt.Println()
+		util.LogName("replayed", "%d requests", len(events))
+		util.LogName("mismatched", "%d requests", failed)
+
+		if failed > 0 {
+			return errors.Errorf("%d of %d responses differ", failed, len(events))
+		}
+
+		return nil
+	})
+}
+
+// replay an event, returning true if the response matches.
+func replay(h apex.Handler, e *record.Event) bool {
+	in := e.Input
+	name := fmt.Sprintf("%s %s", in.HTTPMethod, in.Path)
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		fmt.Printf("     %s %s %s\n", colors.Red("✗"), name, err)
+		return false
+	}
+
+	v, err := h.Handle(b, &apex.Context{})
+	if err != nil {
+		fmt.Printf("     %s %s %s\n", colors.Red("✗"), name, err)
+		return false
+	}
+
+	diffs := record.Diff(e.Output, v.(proxy.Output))
+
+	if len(diffs) == 0 {
+		fmt.Printf("     %s %s %s\n", colors.Green("✓"), name, colors.Gray(fmt.Sprintf("%d", e.Output.StatusCode)))
+		return true
+	}
+
+	fmt.Printf("     %s %s\n", colors.Red("✗"), name)
+	for _, d := range diffs {
+		fmt.Printf("       %s\n", colors.Gray(d))
+	}
+
+	return false
+}
+
+// local returns a handler for the development stage.
+func local(c *up.Config, p *up.Project) (apex.Handler, error) {
+	for k, v := range c.Environment {
+		os.Setenv(k, v)
+	}
+
+	if err := p.Init("development"); err != nil {
+		return nil, errors.Wrap(err, "initializing")
+	}
+
+	if err := c.Override("development"); err != nil {
+		return nil, errors.Wrap(err, "overriding")
+	}
+
+	h, err := handler.FromConfig(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting handler")
+	}
+
+	h, err = handler.New(c, h)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing handler")
+	}
+
+	return proxy.NewHandler(h), nil
+}
+
+// remote returns a handler invoking a deployed stage.
+func remote(c *up.Config, p *up.Project, stage string) (apex.Handler, error) {
+	if err := validate.List(stage, c.Stages.RemoteNames()); err != nil {
+		return nil, err
+	}
+
+	region := c.Regions[0]
+
+	return apex.HandlerFunc(func(event json.RawMessage, _ *apex.Context) (interface{}, error) {
+		res, err := p.Invoke(region, stage, event)
+		if err != nil {
+			return nil, err
+		}
+
+		var out proxy.Output
+		if err := json.Unmarshal(res.Payload, &out); err != nil {
+			return nil, errors.Wrap(err, "parsing response")
+		}
+
+		return out, nil
+	}), nil
+}
@@ -1,32 +1,74 @@
 package run
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tj/kingpin"
+
 	"github.com/apex/up/internal/cli/root"
 	"github.com/apex/up/internal/stats"
 	"github.com/apex/up/internal/util"
-	"github.com/pkg/errors"
-	"github.com/tj/kingpin"
+	"github.com/apex/up/internal/validate"
 )
 
 func init() {
-	cmd := root.Command("run", "Run a hook.")
+	cmd := root.Command("run", "Run a hook or remote command.")
 	cmd.Example(`up run build`, "Run build hook.")
 	cmd.Example(`up run clean`, "Run clean hook.")
+	cmd.Example(`up run --remote production -- ./migrate up`, "Run a command in the production function environment.")
 
-	hook := cmd.Arg("hook", "Name of the hook to run.").Required().String()
+	args := cmd.Arg("hook", "Name of the hook to run, or command when --remote is used.").Required().Strings()
+	stage := cmd.Flag("remote", "Run the command remotely in the given stage.").String()
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
-		_, p, err := root.Init()
+		c, p, err := root.Init()
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
 
 		defer util.Pad()()
 
-		stats.Track("Hook", map[string]interface{}{
-			"name": *hook,
+		if *stage == "" {
+			if len(*args) > 1 {
+				return errors.New("a single hook name is expected")
+			}
+
+			hook := (*args)[0]
+
+			stats.Track("Hook", map[string]interface{}{
+				"name": hook,
+			})
+
+			return p.RunHook(hook)
+		}
+
+		region := c.Regions[0]
+		command := strings.Join(*args, " ")
+
+		stats.Track("Run Remote", map[string]interface{}{
+			"region": region,
+			"stage":  *stage,
 		})
 
-		return p.RunHook(*hook)
+		if err := validate.List(*stage, c.Stages.RemoteNames()); err != nil {
+			return err
+		}
+
+		res, err := p.RunRemote(region, *stage, command)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(os.Stdout, res.Stdout)
+		fmt.Fprint(os.Stderr, res.Stderr)
+
+		if res.ExitCode != 0 {
+			return errors.Errorf("command exited with status %d", res.ExitCode)
+		}
+
+		return nil
 	})
 }
@@ -2,6 +2,7 @@ package url
 
 import (
 	"fmt"
+	neturl "net/url"
 
 	"github.com/pkg/browser"
 	"github.com/pkg/errors"
@@ -9,6 +10,7 @@ import (
 	"github.com/tj/kingpin"
 
 	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/router"
 	"github.com/apex/up/internal/stats"
 	"github.com/apex/up/internal/util"
 	"github.com/apex/up/internal/validate"
@@ -23,10 +25,12 @@ func init() {
 	cmd.Example(`up url production`, "Show the production endpoint.")
 	cmd.Example(`up url -o production`, "Open the production endpoint in the browser.")
 	cmd.Example(`up url -c production`, "Copy the production endpoint to the clipboard.")
+	cmd.Example(`up url --commit 1a2b3c`, "Show the staging endpoint for a specific commit.")
 
 	stage := cmd.Arg("stage", "Name of the stage.").Default("staging").String()
 	open := cmd.Flag("open", "Open endpoint in the browser.").Short('o').Bool()
 	copy := cmd.Flag("copy", "Copy endpoint to the clipboard.").Short('c').Bool()
+	commit := cmd.Flag("commit", "Git commit or tag to route to.").String()
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
 		c, p, err := root.Init()
@@ -41,6 +45,7 @@ func init() {
 			"stage":  *stage,
 			"open":   *open,
 			"copy":   *copy,
+			"commit": *commit != "",
 		})
 
 		if err := validate.List(*stage, c.Stages.RemoteNames()); err != nil {
@@ -52,6 +57,10 @@ func init() {
 			return err
 		}
 
+		if *commit != "" {
+			url += "?" + router.Query + "=" + neturl.QueryEscape(*commit)
+		}
+
 		switch {
 		case *open:
 			browser.OpenURL(url)
@@ -0,0 +1,140 @@
+// Package csp provides a Content-Security-Policy builder with nonce support.
+package csp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Nonce is the source expression replaced by a per-request nonce.
+const Nonce = "'nonce'"
+
+// directive name pattern.
+var directive = regexp.MustCompile(`^[a-z]+(-[a-z]+)*$`)
+
+// Policy is a map of directive names to source lists, such as
+// "script-src" to ["'self'", "'nonce'"]. Directives such as
+// "upgrade-insecure-requests" have an empty source list.
+type Policy map[string][]string
+
+// Validate implementation.
+func (p Policy) Validate() error {
+	for name, sources := range p {
+		if !directive.MatchString(name) {
+			return errors.Errorf("invalid directive %q", name)
+		}
+
+		for _, s := range sources {
+			if s == "" || strings.ContainsAny(s, ";, \t\r\n") {
+				return errors.Errorf("invalid source %q for directive %q", s, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Nonced returns true if the policy contains a nonce source.
+func (p Policy) Nonced() bool {
+	for _, sources := range p {
+		for _, s := range sources {
+			if s == Nonce {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// String returns the header field value of the policy, replacing nonce
+// sources with the given nonce. Directives are sorted by name, with
+// "default-src" first.
+func (p Policy) String(nonce string) string {
+	var names []string
+	for name := range p {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "default-src" || names[j] == "default-src" {
+			return names[i] == "default-src"
+		}
+		return names[i] < names[j]
+	})
+
+	var directives []string
+
+	for _, name := range names {
+		parts := []string{name}
+
+		for _, s := range p[name] {
+			if s == Nonce {
+				s = "'nonce-" + nonce + "'"
+			}
+			parts = append(parts, s)
+		}
+
+		directives = append(directives, strings.Join(parts, " "))
+	}
+
+	return strings.Join(directives, "; ")
+}
+
+// Merge returns a new policy with directives of o replacing those of p.
+func (p Policy) Merge(o Policy) Policy {
+	m := make(Policy)
+
+	for name, sources := range p {
+		m[name] = sources
+	}
+
+	for name, sources := range o {
+		m[name] = sources
+	}
+
+	return m
+}
+
+// NewNonce returns a random base64 encoded nonce.
+func NewNonce() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "reading random bytes"))
+	}
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// key is the context key of the nonce.
+type key struct{}
+
+// NewContext returns a new context with nonce.
+func NewContext(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, key{}, nonce)
+}
+
+// FromContext returns the nonce from context.
+func FromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(key{}).(string)
+	return v, ok
+}
+
+// WithNonce returns the request with a nonce in its context, generating
+// one when missing, so that middleware share a nonce per request.
+func WithNonce(r *http.Request) (*http.Request, string) {
+	if nonce, ok := FromContext(r.Context()); ok {
+		return r, nonce
+	}
+
+	nonce := NewNonce()
+	return r.WithContext(NewContext(r.Context(), nonce)), nonce
+}
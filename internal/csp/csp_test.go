@@ -0,0 +1,44 @@
+package csp
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestPolicy_String(t *testing.T) {
+	p := Policy{
+		"script-src":                {"'self'", Nonce},
+		"default-src":               {"'self'"},
+		"object-src":                {"'none'"},
+		"upgrade-insecure-requests": {},
+	}
+
+	assert.True(t, p.Nonced())
+	assert.Equal(t, "default-src 'self'; object-src 'none'; script-src 'self' 'nonce-abc'; upgrade-insecure-requests", p.String("abc"))
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, Policy{"img-src": {"'self'", "data:"}}.Validate())
+	assert.EqualError(t, Policy{"Img Src": {"'self'"}}.Validate(), `invalid directive "Img Src"`)
+	assert.EqualError(t, Policy{"img-src": {"'self'; script-src *"}}.Validate(), `invalid source "'self'; script-src *" for directive "img-src"`)
+}
+
+func TestPolicy_Merge(t *testing.T) {
+	a := Policy{"default-src": {"'self'"}, "img-src": {"'self'"}}
+	b := Policy{"img-src": {"*"}}
+	assert.Equal(t, Policy{"default-src": {"'self'"}, "img-src": {"*"}}, a.Merge(b))
+	assert.Equal(t, []string{"'self'"}, a["img-src"])
+}
+
+func TestWithNonce(t *testing.T) {
+	r, nonce := WithNonce(httptest.NewRequest("GET", "/", nil))
+	assert.Len(t, nonce, 24)
+
+	r, again := WithNonce(r)
+	assert.Equal(t, nonce, again)
+
+	_, other := WithNonce(httptest.NewRequest("GET", "/", nil))
+	assert.NotEqual(t, nonce, other)
+}
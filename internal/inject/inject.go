@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"html"
 	"io/ioutil"
+	"regexp"
 	"strings"
 
 	"github.com/apex/log"
@@ -33,6 +34,9 @@ var types = []string{
 	"segment",
 }
 
+// tags which support the nonce attribute.
+var tags = regexp.MustCompile(`(?i)<(script|style|link)\b`)
+
 // Rules is a set of rules mapped by location.
 type Rules map[string][]*Rule
 
@@ -68,15 +72,27 @@ func (r Rules) Validate() error {
 
 // Apply rules to html.
 func (r Rules) Apply(html string) string {
+	return r.ApplyNonce(html, "")
+}
+
+// ApplyNonce applies rules to html, adding the nonce
+// attribute to injected tags when nonce is non-empty.
+func (r Rules) ApplyNonce(html, nonce string) string {
 	for pos, rules := range r {
 		log.Debugf("injecting %s rules", pos)
 		for _, rule := range rules {
 			log.Debugf("  inject %s %q", rule.Type, rule.Value)
+
+			s := rule.Apply(html)
+			if nonce != "" {
+				s = Nonce(s, nonce)
+			}
+
 			switch pos {
 			case "head":
-				html = Head(html, rule.Apply(html))
+				html = Head(html, s)
 			case "body":
-				html = Body(html, rule.Apply(html))
+				html = Body(html, s)
 			}
 		}
 	}
@@ -192,6 +208,11 @@ func StyleInline(s string) string {
 	return `<style>` + s + `</style>`
 }
 
+// Nonce adds the nonce attribute to script, style and link tags.
+func Nonce(s, nonce string) string {
+	return tags.ReplaceAllString(s, `<$1 nonce="`+html.EscapeString(nonce)+`"`)
+}
+
 // Comment returns an html comment.
 func Comment(s string) string {
 	return "<!-- " + html.EscapeString(s) + " -->"
@@ -44,6 +44,14 @@ func ExampleScriptInline() {
 	// <script>const user = { "name": "Tobi" }</script>
 }
 
+func ExampleNonce() {
+	fmt.Printf("%s\n", inject.Nonce(inject.ScriptInline(`ready()`), "abc"))
+	fmt.Printf("%s\n", inject.Nonce(inject.Style(`/sloth.css`), "abc"))
+	// Output:
+	// <script nonce="abc">ready()</script>
+	// <link nonce="abc" rel="stylesheet" href="/sloth.css">
+}
+
 func ExampleComment() {
 	fmt.Printf("%s\n", inject.Comment(`Hello World`))
 	// Output:
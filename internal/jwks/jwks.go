@@ -0,0 +1,277 @@
+// Package jwks provides JSON Web Key Set parsing and caching,
+// with refreshing of keys when rotated by the provider.
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Keys map of key ids to public keys.
+type Keys map[string]interface{}
+
+// key is a JSON Web Key.
+type key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Parse a JSON Web Key Set, ignoring unsupported keys.
+func Parse(r io.Reader) (Keys, error) {
+	var set struct {
+		Keys []key `json:"keys"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, errors.Wrap(err, "decoding")
+	}
+
+	keys := make(Keys)
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.public()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %q", k.Kid)
+		}
+
+		if pub != nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	return keys, nil
+}
+
+// ReadFile parses a JSON Web Key Set from path.
+func ReadFile(path string) (Keys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// public returns the public key, or nil when unsupported.
+func (k *key) public() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding modulus")
+		}
+
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding x")
+		}
+
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding y")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Set is a cached JSON Web Key Set.
+type Set struct {
+	// URL of the key set.
+	URL string
+
+	// Issuer used for OpenID Connect discovery of the URL.
+	Issuer string
+
+	// TTL of the cached keys.
+	TTL time.Duration
+
+	// MinRefresh is the minimum interval between refreshes
+	// triggered by an unknown key id, such as after rotation.
+	MinRefresh time.Duration
+
+	// Client used for requests.
+	Client *http.Client
+
+	mu      sync.Mutex
+	keys    Keys
+	static  bool
+	fetched time.Time
+	now     func() time.Time
+}
+
+// New returns a set fetched from url, or discovered
+// from the issuer's OpenID configuration when empty.
+func New(url, issuer string) *Set {
+	return &Set{
+		URL:        url,
+		Issuer:     issuer,
+		TTL:        time.Hour,
+		MinRefresh: time.Minute,
+		Client:     http.DefaultClient,
+		now:        time.Now,
+	}
+}
+
+// NewStatic returns a set of static keys.
+func NewStatic(keys Keys) *Set {
+	return &Set{keys: keys, static: true, now: time.Now}
+}
+
+// Key returns the public key for the given key id. When
+// the id is empty and the set contains a single key,
+// that key is returned.
+func (s *Set) Key(kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	age := now.Sub(s.fetched)
+
+	if s.keys != nil && (s.static || age < s.TTL) {
+		if k, ok := s.lookup(kid); ok {
+			return k, nil
+		}
+
+		if s.static || age < s.MinRefresh {
+			return nil, errors.Errorf("unknown key %q", kid)
+		}
+	}
+
+	if err := s.fetch(); err != nil {
+		return nil, errors.Wrap(err, "fetching keys")
+	}
+
+	s.fetched = now
+
+	if k, ok := s.lookup(kid); ok {
+		return k, nil
+	}
+
+	return nil, errors.Errorf("unknown key %q", kid)
+}
+
+// lookup returns the key by id.
+func (s *Set) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// fetch the keys.
+func (s *Set) fetch() error {
+	if s.URL == "" {
+		url, err := s.discover()
+		if err != nil {
+			return errors.Wrap(err, "discovering")
+		}
+		s.URL = url
+	}
+
+	res, err := s.Client.Get(s.URL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errors.Errorf("%s response", res.Status)
+	}
+
+	keys, err := Parse(res.Body)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	return nil
+}
+
+// discover the key set url from the issuer's OpenID configuration.
+func (s *Set) discover() (string, error) {
+	url := strings.TrimSuffix(s.Issuer, "/") + "/.well-known/openid-configuration"
+
+	res, err := s.Client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return "", errors.Errorf("%s response", res.Status)
+	}
+
+	var config struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&config); err != nil {
+		return "", errors.Wrap(err, "decoding")
+	}
+
+	if config.JWKSURI == "" {
+		return "", errors.New("missing jwks_uri")
+	}
+
+	return config.JWKSURI, nil
+}
+
+// decodeInt decodes a base64url encoded big-endian integer.
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
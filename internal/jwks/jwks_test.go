@@ -0,0 +1,206 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+// server is a local JWKS server.
+type server struct {
+	*httptest.Server
+	sync.Mutex
+	keys     map[string]*rsa.PublicKey
+	requests int
+}
+
+// newServer returns a new JWKS server.
+func newServer() *server {
+	s := &server{keys: make(map[string]*rsa.PublicKey)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Add a key.
+func (s *server) Add(kid string, k *rsa.PublicKey) {
+	s.Lock()
+	defer s.Unlock()
+	s.keys[kid] = k
+}
+
+// Requests returns the number of key set requests.
+func (s *server) Requests() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests
+}
+
+// ServeHTTP implementation.
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if r.URL.Path == "/.well-known/openid-configuration" {
+		json.NewEncoder(w).Encode(map[string]string{
+			"jwks_uri": s.URL + "/keys",
+		})
+		return
+	}
+
+	s.requests++
+
+	var keys []map[string]string
+	for kid, k := range s.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   encode(k.N),
+			"e":   encode(big.NewInt(int64(k.E))),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+// encode an integer.
+func encode(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// generate an RSA key.
+func generate(t testing.TB) *rsa.PrivateKey {
+	k, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err, "generate")
+	return k
+}
+
+func TestParse(t *testing.T) {
+	rk := generate(t)
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "generate")
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": encode(rk.N), "e": encode(big.NewInt(int64(rk.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ek.X), "y": encode(ek.Y)},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(rk.N), "e": "AQAB"},
+			{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+		},
+	}
+
+	b, err := json.Marshal(set)
+	assert.NoError(t, err, "marshal")
+
+	keys, err := Parse(strings.NewReader(string(b)))
+	assert.NoError(t, err, "parse")
+	assert.Len(t, keys, 2)
+	assert.Equal(t, &rk.PublicKey, keys["rsa"])
+	assert.Equal(t, &ek.PublicKey, keys["ec"])
+}
+
+func TestParse_invalidCurve(t *testing.T) {
+	_, err := Parse(strings.NewReader(`{ "keys": [{ "kty": "EC", "kid": "a", "crv": "P-1" }] }`))
+	assert.EqualError(t, err, `key "a": unsupported curve "P-1"`)
+}
+
+func TestSet_Key(t *testing.T) {
+	a, b := generate(t), generate(t)
+
+	s := newServer()
+	defer s.Close()
+	s.Add("a", &a.PublicKey)
+
+	now := time.Now()
+	set := New(s.URL+"/keys", "")
+	set.now = func() time.Time { return now }
+
+	t.Run("fetch", func(t *testing.T) {
+		k, err := set.Key("a")
+		assert.NoError(t, err, "key")
+		assert.Equal(t, &a.PublicKey, k)
+		assert.Equal(t, 1, s.Requests())
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		k, err := set.Key("a")
+		assert.NoError(t, err, "key")
+		assert.Equal(t, &a.PublicKey, k)
+		assert.Equal(t, 1, s.Requests())
+	})
+
+	t.Run("without kid", func(t *testing.T) {
+		k, err := set.Key("")
+		assert.NoError(t, err, "key")
+		assert.Equal(t, &a.PublicKey, k)
+	})
+
+	t.Run("rotated before min refresh", func(t *testing.T) {
+		s.Add("b", &b.PublicKey)
+		_, err := set.Key("b")
+		assert.EqualError(t, err, `unknown key "b"`)
+		assert.Equal(t, 1, s.Requests())
+	})
+
+	t.Run("rotated after min refresh", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		k, err := set.Key("b")
+		assert.NoError(t, err, "key")
+		assert.Equal(t, &b.PublicKey, k)
+		assert.Equal(t, 2, s.Requests())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, err := set.Key("c")
+		assert.EqualError(t, err, `unknown key "c"`)
+		assert.Equal(t, 3, s.Requests())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		k, err := set.Key("a")
+		assert.NoError(t, err, "key")
+		assert.Equal(t, &a.PublicKey, k)
+		assert.Equal(t, 4, s.Requests())
+	})
+}
+
+func TestSet_Key_discovery(t *testing.T) {
+	a := generate(t)
+
+	s := newServer()
+	defer s.Close()
+	s.Add("a", &a.PublicKey)
+
+	set := New("", s.URL+"/")
+
+	k, err := set.Key("a")
+	assert.NoError(t, err, "key")
+	assert.Equal(t, &a.PublicKey, k)
+	assert.Equal(t, s.URL+"/keys", set.URL)
+}
+
+func TestNewStatic(t *testing.T) {
+	a := generate(t)
+	set := NewStatic(Keys{"a": &a.PublicKey})
+
+	k, err := set.Key("a")
+	assert.NoError(t, err, "key")
+	assert.Equal(t, &a.PublicKey, k)
+
+	_, err = set.Key("b")
+	assert.EqualError(t, err, `unknown key "b"`)
+}
@@ -26,7 +26,7 @@ type RequestContext struct {
 	AccountID    string
 	Stage        string
 	Identity     Identity
-	Authorizer   map[string]string `json:"-"`
+	Authorizer   map[string]interface{} `json:",omitempty"`
 }
 
 // Input is the input provided by API Gateway.
@@ -1,15 +1,49 @@
 package proxy
 
 import (
+	"context"
 	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/dchest/uniuri"
 	"github.com/pkg/errors"
 )
 
+// authorizerKey is the context key for the authorizer context.
+type authorizerKey struct{}
+
+// Authorizer returns the API Gateway authorizer context of the request.
+func Authorizer(r *http.Request) map[string]interface{} {
+	v, _ := r.Context().Value(authorizerKey{}).(map[string]interface{})
+	return v
+}
+
+// Claim returns the value of an authorizer claim, looked up in the
+// "claims" map populated by Cognito and JWT authorizers, or the
+// context returned by custom authorizers.
+func Claim(r *http.Request, name string) string {
+	a := Authorizer(r)
+
+	if claims, ok := a["claims"].(map[string]interface{}); ok {
+		if v, ok := claims[name]; ok {
+			return fmt.Sprint(v)
+		}
+	}
+
+	if v, ok := a[name]; ok {
+		return fmt.Sprint(v)
+	}
+
+	return ""
+}
+
 // NewRequest returns a new http.Request from the given Lambda event.
 func NewRequest(e *Input) (*http.Request, error) {
 	// path
@@ -62,5 +96,76 @@ func NewRequest(e *Input) (*http.Request, error) {
 	req.URL.Host = req.Header.Get("Host")
 	req.Host = req.URL.Host
 
+	// authorizer
+	if a := e.RequestContext.Authorizer; a != nil {
+		req = req.WithContext(context.WithValue(req.Context(), authorizerKey{}, a))
+	}
+
 	return req, nil
 }
+
+// NewInput returns a new Lambda event from the given http.Request,
+// mirroring the event API Gateway produces for proxy integrations.
+func NewInput(req *http.Request) (*Input, error) {
+	var body []byte
+
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading body")
+		}
+		body = b
+	}
+
+	e := &Input{
+		HTTPMethod: req.Method,
+		Resource:   "/{proxy+}",
+		Path:       req.URL.Path,
+		Headers:    make(map[string]string),
+		PathParameters: map[string]string{
+			"proxy": strings.TrimPrefix(req.URL.Path, "/"),
+		},
+		RequestContext: RequestContext{
+			RequestID:    uniuri.New(),
+			HTTPMethod:   req.Method,
+			ResourcePath: "/{proxy+}",
+			Identity: Identity{
+				UserAgent: req.UserAgent(),
+				SourceIP:  req.RemoteAddr,
+			},
+		},
+	}
+
+	// remote addr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		e.RequestContext.Identity.SourceIP = host
+	}
+
+	// querystring
+	if q := req.URL.Query(); len(q) > 0 {
+		e.QueryStringParameters = make(map[string]string)
+		for k := range q {
+			e.QueryStringParameters[k] = q.Get(k)
+		}
+	}
+
+	// header fields
+	for k := range req.Header {
+		e.Headers[k] = req.Header.Get(k)
+	}
+
+	// host
+	if req.Host != "" {
+		e.Headers["Host"] = req.Host
+	}
+
+	// body
+	if utf8.Valid(body) {
+		e.Body = string(body)
+	} else {
+		e.Body = base64.StdEncoding.EncodeToString(body)
+		e.IsBase64Encoded = true
+	}
+
+	return e, nil
+}
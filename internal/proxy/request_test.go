@@ -1,8 +1,10 @@
 package proxy
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/tj/assert"
@@ -79,3 +81,65 @@ func TestNewRequest(t *testing.T) {
 		assert.True(t, ok)
 	})
 }
+
+func TestNewInput(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://example.com/pets/tobi?format=json", nil)
+		r.Header.Set("Accept", "application/json")
+
+		in, err := NewInput(r)
+		assert.NoError(t, err, "new input")
+
+		assert.Equal(t, "GET", in.HTTPMethod)
+		assert.Equal(t, "/pets/tobi", in.Path)
+		assert.Equal(t, "pets/tobi", in.PathParameters["proxy"])
+		assert.Equal(t, "json", in.QueryStringParameters["format"])
+		assert.Equal(t, "application/json", in.Headers["Accept"])
+		assert.Equal(t, "example.com", in.Headers["Host"])
+		assert.Equal(t, "192.0.2.1", in.RequestContext.Identity.SourceIP)
+		assert.NotEmpty(t, in.RequestContext.RequestID)
+
+		req, err := NewRequest(in)
+		assert.NoError(t, err, "new request")
+		assert.Equal(t, "example.com", req.Host)
+		assert.Equal(t, "/pets/tobi", req.URL.Path)
+		assert.Equal(t, "format=json", req.URL.Query().Encode())
+	})
+
+	t.Run("POST binary", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/upload", bytes.NewReader([]byte{0xff, 0xfe, 0x00}))
+
+		in, err := NewInput(r)
+		assert.NoError(t, err, "new input")
+		assert.True(t, in.IsBase64Encoded)
+
+		req, err := NewRequest(in)
+		assert.NoError(t, err, "new request")
+
+		b, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err, "read body")
+		assert.Equal(t, []byte{0xff, 0xfe, 0x00}, b)
+	})
+}
+
+func TestClaim(t *testing.T) {
+	var in Input
+	err := json.Unmarshal([]byte(getEvent), &in)
+	assert.NoError(t, err, "unmarshal")
+
+	in.RequestContext.Authorizer = map[string]interface{}{
+		"principalId": "user-1",
+		"claims": map[string]interface{}{
+			"sub":   "1234",
+			"email": "tobi@apex.sh",
+		},
+	}
+
+	req, err := NewRequest(&in)
+	assert.NoError(t, err, "new request")
+
+	assert.Equal(t, "1234", Claim(req, "sub"))
+	assert.Equal(t, "tobi@apex.sh", Claim(req, "email"))
+	assert.Equal(t, "user-1", Claim(req, "principalId"))
+	assert.Equal(t, "", Claim(req, "missing"))
+}
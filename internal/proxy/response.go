@@ -85,7 +85,7 @@ func isBinary(h http.Header) bool {
 		return true
 	}
 
-	if h.Get("Content-Encoding") == "gzip" {
+	if e := h.Get("Content-Encoding"); e != "" && e != "identity" {
 		return true
 	}
 
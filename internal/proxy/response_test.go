@@ -81,6 +81,18 @@ func TestResponseWriter_Write_gzip(t *testing.T) {
 	assert.True(t, e.IsBase64Encoded)
 }
 
+func TestResponseWriter_Write_brotli(t *testing.T) {
+	w := NewResponse()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "br")
+	w.Write([]byte("data"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "ZGF0YQ==", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+}
+
 func TestResponseWriter_WriteHeader(t *testing.T) {
 	w := NewResponse()
 	w.WriteHeader(404)
@@ -0,0 +1,182 @@
+// Package ratelimit provides path-matched rate limiting rules.
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fanyang01/radix"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/proxy"
+)
+
+// Rule is a rate limiting rule.
+type Rule struct {
+	// Key used to identify clients, one of "ip",
+	// "header:<name>" or "claim:<name>". Defaults to "ip".
+	Key string `json:"key"`
+
+	// Requests allowed per window.
+	Requests int `json:"requests"`
+
+	// Window is the duration of the window such as "1m". Defaults to "1m".
+	Window string `json:"window"`
+
+	// Burst is the maximum number of requests allowed
+	// at once. Defaults to Requests.
+	Burst int `json:"burst"`
+}
+
+// Default implementation.
+func (r *Rule) Default() error {
+	if r.Key == "" {
+		r.Key = "ip"
+	}
+
+	if r.Window == "" {
+		r.Window = "1m"
+	}
+
+	if r.Burst == 0 {
+		r.Burst = r.Requests
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (r *Rule) Validate() error {
+	switch {
+	case r.Key == "ip":
+	case strings.HasPrefix(r.Key, "header:") && len(r.Key) > len("header:"):
+	case strings.HasPrefix(r.Key, "claim:") && len(r.Key) > len("claim:"):
+	default:
+		return errors.Errorf(".key %q is invalid, must be one of ip, header:<name> or claim:<name>", r.Key)
+	}
+
+	if r.Requests <= 0 {
+		return errors.New(".requests must be greater than 0")
+	}
+
+	d, err := time.ParseDuration(r.Window)
+	if err != nil {
+		return errors.Wrap(err, ".window")
+	}
+
+	if d <= 0 {
+		return errors.New(".window must be greater than 0")
+	}
+
+	if r.Burst < 0 {
+		return errors.New(".burst must be positive")
+	}
+
+	return nil
+}
+
+// Limit returns the limit for the rule.
+func (r *Rule) Limit() Limit {
+	d, _ := time.ParseDuration(r.Window)
+	if d <= 0 {
+		d = time.Minute
+	}
+
+	burst := r.Burst
+	if burst == 0 {
+		burst = r.Requests
+	}
+
+	return Limit{
+		Rate:  float64(r.Requests) / d.Seconds(),
+		Burst: burst,
+	}
+}
+
+// ClientKey returns the client key for the request, falling
+// back on the ip address when the header or claim is missing.
+func (r *Rule) ClientKey(req *http.Request) string {
+	switch {
+	case strings.HasPrefix(r.Key, "header:"):
+		if v := req.Header.Get(strings.TrimPrefix(r.Key, "header:")); v != "" {
+			return r.Key + "=" + v
+		}
+	case strings.HasPrefix(r.Key, "claim:"):
+		if v := proxy.Claim(req, strings.TrimPrefix(r.Key, "claim:")); v != "" {
+			return r.Key + "=" + v
+		}
+	}
+
+	return "ip=" + ip(req)
+}
+
+// Rules map of paths to rules.
+type Rules map[string]*Rule
+
+// Default rules.
+func (r Rules) Default() error {
+	for path, rule := range r {
+		if err := rule.Default(); err != nil {
+			return errors.Wrapf(err, "%s", path)
+		}
+	}
+
+	return nil
+}
+
+// Validate rules.
+func (r Rules) Validate() error {
+	for path, rule := range r {
+		if err := rule.Validate(); err != nil {
+			return errors.Wrapf(err, "%s", path)
+		}
+	}
+
+	return nil
+}
+
+// Matcher for rule lookup.
+type Matcher struct {
+	t *radix.PatternTrie
+}
+
+// match is a rule and its pattern.
+type match struct {
+	path string
+	rule *Rule
+}
+
+// Lookup returns the rule and its path pattern for the given path.
+func (m *Matcher) Lookup(path string) (string, *Rule) {
+	v, ok := m.t.Lookup(path)
+	if !ok {
+		return "", nil
+	}
+
+	r := v.(match)
+	return r.path, r.rule
+}
+
+// Compile the given rules to a trie.
+func Compile(rules Rules) (*Matcher, error) {
+	t := radix.NewPatternTrie()
+	m := &Matcher{t}
+
+	for path, rule := range rules {
+		t.Add(path, match{path, rule})
+	}
+
+	return m, nil
+}
+
+// ip returns the client ip address.
+func ip(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestRule_Validate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		r := &Rule{Requests: 10}
+		assert.NoError(t, r.Default(), "default")
+		assert.NoError(t, r.Validate(), "validate")
+		assert.Equal(t, "ip", r.Key)
+		assert.Equal(t, "1m", r.Window)
+		assert.Equal(t, 10, r.Burst)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		r := &Rule{Requests: 10, Key: "header:"}
+		assert.NoError(t, r.Default(), "default")
+		assert.EqualError(t, r.Validate(), `.key "header:" is invalid, must be one of ip, header:<name> or claim:<name>`)
+	})
+
+	t.Run("missing requests", func(t *testing.T) {
+		r := &Rule{}
+		assert.NoError(t, r.Default(), "default")
+		assert.EqualError(t, r.Validate(), `.requests must be greater than 0`)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		r := &Rule{Requests: 10, Window: "soon"}
+		assert.NoError(t, r.Default(), "default")
+		assert.Error(t, r.Validate())
+	})
+}
+
+func TestRule_Limit(t *testing.T) {
+	r := &Rule{Requests: 120, Window: "1m", Burst: 20}
+	assert.Equal(t, Limit{Rate: 2, Burst: 20}, r.Limit())
+}
+
+func TestRule_ClientKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Api-Key", "abc")
+
+	r := &Rule{Key: "ip"}
+	assert.Equal(t, "ip=192.0.2.1", r.ClientKey(req))
+
+	r = &Rule{Key: "header:X-Api-Key"}
+	assert.Equal(t, "header:X-Api-Key=abc", r.ClientKey(req))
+
+	r = &Rule{Key: "header:X-Missing"}
+	assert.Equal(t, "ip=192.0.2.1", r.ClientKey(req))
+
+	r = &Rule{Key: "claim:sub"}
+	assert.Equal(t, "ip=192.0.2.1", r.ClientKey(req))
+}
+
+func TestMatcher_Lookup(t *testing.T) {
+	api := &Rule{Requests: 1}
+	login := &Rule{Requests: 2}
+
+	m, err := Compile(Rules{
+		"/api/*": api,
+		"/login": login,
+	})
+
+	assert.NoError(t, err, "compile")
+
+	path, rule := m.Lookup("/api/pets")
+	assert.Equal(t, "/api/*", path)
+	assert.Equal(t, api, rule)
+
+	path, rule = m.Lookup("/login")
+	assert.Equal(t, "/login", path)
+	assert.Equal(t, login, rule)
+
+	_, rule = m.Lookup("/")
+	assert.Nil(t, rule)
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket limit.
+type Limit struct {
+	// Rate is the number of tokens added per second.
+	Rate float64
+
+	// Burst is the capacity of the bucket.
+	Burst int
+}
+
+// Result of taking a token.
+type Result struct {
+	// Allowed is true when a token was available.
+	Allowed bool
+
+	// Remaining is the number of tokens remaining.
+	Remaining int
+
+	// Reset is the time until the bucket is full.
+	Reset time.Duration
+
+	// RetryAfter is the time until a token is available
+	// when the request was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store is the interface used for storing buckets.
+type Store interface {
+	Take(key string, l Limit) (*Result, error)
+}
+
+// bucket is a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore is an in-memory token bucket store.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	takes   int
+}
+
+// NewMemoryStore returns a new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take implementation.
+func (s *MemoryStore) Take(key string, l Limit) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	burst := float64(l.Burst)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	// refill
+	b.limit = l
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+
+	res := &Result{}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / l.Rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / l.Rate)
+
+	s.takes++
+	if s.takes%1000 == 0 {
+		s.prune(now)
+	}
+
+	return res, nil
+}
+
+// prune removes buckets which would be full by now.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// seconds returns a duration from seconds.
+func seconds(n float64) time.Duration {
+	return time.Duration(n * float64(time.Second))
+}
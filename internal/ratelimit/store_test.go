@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	l := Limit{Rate: 1, Burst: 2}
+
+	res, err := s.Take("a", l)
+	assert.NoError(t, err, "take")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+
+	res, err = s.Take("a", l)
+	assert.NoError(t, err, "take")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	res, err = s.Take("a", l)
+	assert.NoError(t, err, "take")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	res, err = s.Take("b", l)
+	assert.NoError(t, err, "take")
+	assert.True(t, res.Allowed, "separate keys")
+
+	now = now.Add(time.Second)
+
+	res, err = s.Take("a", l)
+	assert.NoError(t, err, "take")
+	assert.True(t, res.Allowed, "refilled")
+}
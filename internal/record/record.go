@@ -0,0 +1,285 @@
+// Package record provides sampled recording of API Gateway events,
+// and comparison of recorded responses for replays.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/go-apex"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/proxy"
+)
+
+// log context.
+var ctx = logs.Plugin("record")
+
+// redacted value.
+const redacted = "[REDACTED]"
+
+// redactDefaults is a list of names always redacted.
+var redactDefaults = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// ignoredHeaders is a list of header fields
+// which are expected to change between responses.
+var ignoredHeaders = map[string]bool{
+	"Date":         true,
+	"X-Request-Id": true,
+}
+
+// Event is a recorded request and response pair.
+type Event struct {
+	Time   time.Time    `json:"time"`
+	Stage  string       `json:"stage"`
+	Input  proxy.Input  `json:"input"`
+	Output proxy.Output `json:"output"`
+}
+
+// Store is the interface used for persisting events.
+type Store interface {
+	Put(*Event) error
+}
+
+// NewStore returns a store for the given output, which
+// is either a file path or an s3://bucket/prefix url.
+func NewStore(output string) (Store, error) {
+	if strings.HasPrefix(output, "s3://") {
+		u, err := url.Parse(output)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing s3 url")
+		}
+
+		return NewS3Store(u.Host, strings.TrimPrefix(u.Path, "/")), nil
+	}
+
+	return NewFileStore(output), nil
+}
+
+// Recorder records a sample of events.
+type Recorder struct {
+	store  Store
+	stage  string
+	sample float64
+	redact map[string]bool
+}
+
+// New recorder.
+func New(c *config.Recording, stage string, store Store) *Recorder {
+	r := &Recorder{
+		store:  store,
+		stage:  stage,
+		sample: c.Sample,
+		redact: make(map[string]bool),
+	}
+
+	for _, name := range append(redactDefaults, c.Redact...) {
+		r.redact[strings.ToLower(name)] = true
+	}
+
+	return r
+}
+
+// NewHandler returns a handler recording a sample of events handled by h.
+func NewHandler(c *config.Recording, stage string, h apex.Handler) (apex.Handler, error) {
+	store, err := NewStore(c.Output)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating store")
+	}
+
+	r := New(c, stage, store)
+
+	return apex.HandlerFunc(func(event json.RawMessage, c *apex.Context) (interface{}, error) {
+		v, err := h.Handle(event, c)
+		if err != nil || !r.sampled() {
+			return v, err
+		}
+
+		out, ok := v.(proxy.Output)
+		if !ok {
+			return v, err
+		}
+
+		if err := r.Record(event, out); err != nil {
+			ctx.WithError(err).Error("recording event")
+		}
+
+		return v, err
+	}), nil
+}
+
+// Record the given raw event and its output.
+func (r *Recorder) Record(event json.RawMessage, out proxy.Output) error {
+	var in proxy.Input
+
+	if err := json.Unmarshal(event, &in); err != nil {
+		return errors.Wrap(err, "parsing event")
+	}
+
+	e := &Event{
+		Time:   time.Now(),
+		Stage:  r.stage,
+		Input:  r.redactInput(in),
+		Output: r.redactOutput(out),
+	}
+
+	return r.store.Put(e)
+}
+
+// sampled returns true if the event should be recorded.
+func (r *Recorder) sampled() bool {
+	return rand.Float64() < r.sample
+}
+
+// redactInput returns a copy of the input with sensitive fields redacted.
+func (r *Recorder) redactInput(in proxy.Input) proxy.Input {
+	in.Headers = r.redactMap(in.Headers)
+	in.QueryStringParameters = r.redactMap(in.QueryStringParameters)
+	return in
+}
+
+// redactOutput returns a copy of the output with sensitive fields redacted.
+func (r *Recorder) redactOutput(out proxy.Output) proxy.Output {
+	out.Headers = r.redactMap(out.Headers)
+	return out
+}
+
+// redactMap returns a copy of m with sensitive values redacted.
+func (r *Recorder) redactMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	v := make(map[string]string, len(m))
+
+	for name, val := range m {
+		if r.redact[strings.ToLower(name)] {
+			val = redacted
+		}
+		v[name] = val
+	}
+
+	return v
+}
+
+// Read events from r, which contains newline-delimited JSON.
+func Read(r io.Reader) (events []*Event, err error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 10<<20)
+
+	for line := 1; s.Scan(); line++ {
+		b := bytes.TrimSpace(s.Bytes())
+
+		if len(b) == 0 {
+			continue
+		}
+
+		var e Event
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+
+		events = append(events, &e)
+	}
+
+	return events, s.Err()
+}
+
+// ReadFile reads events from the given path.
+func ReadFile(path string) ([]*Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Diff returns a list of differences between the
+// expected output and the actual output.
+func Diff(expected, actual proxy.Output) (diffs []string) {
+	if expected.StatusCode != actual.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status %d → %d", expected.StatusCode, actual.StatusCode))
+	}
+
+	a := http.Header{}
+	for k, v := range expected.Headers {
+		a.Set(k, v)
+	}
+
+	b := http.Header{}
+	for k, v := range actual.Headers {
+		b.Set(k, v)
+	}
+
+	for _, name := range headerNames(a, b) {
+		x, y := a.Get(name), b.Get(name)
+
+		switch {
+		case ignoredHeaders[name] || x == redacted || x == y:
+			continue
+		case x == "":
+			diffs = append(diffs, fmt.Sprintf("header %s added %q", name, y))
+		case y == "":
+			diffs = append(diffs, fmt.Sprintf("header %s removed %q", name, x))
+		default:
+			diffs = append(diffs, fmt.Sprintf("header %s %q → %q", name, x, y))
+		}
+	}
+
+	if !bytes.Equal(Body(expected), Body(actual)) {
+		diffs = append(diffs, "body")
+	}
+
+	return
+}
+
+// Body returns the decoded response body.
+func Body(out proxy.Output) []byte {
+	if !out.IsBase64Encoded {
+		return []byte(out.Body)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(out.Body)
+	if err != nil {
+		return []byte(out.Body)
+	}
+
+	return b
+}
+
+// headerNames returns the sorted union of field names.
+func headerNames(a, b http.Header) (names []string) {
+	for name := range a {
+		names = append(names, name)
+	}
+
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return
+}
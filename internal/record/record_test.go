@@ -0,0 +1,163 @@
+package record
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/proxy"
+)
+
+type memoryStore struct {
+	events []*Event
+}
+
+func (s *memoryStore) Put(e *Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func TestRecorder_Record(t *testing.T) {
+	s := &memoryStore{}
+	r := New(&config.Recording{Sample: 1, Redact: []string{"token"}}, "production", s)
+
+	in := proxy.Input{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+			"Accept":        "application/json",
+		},
+		QueryStringParameters: map[string]string{
+			"token": "secret",
+			"page":  "2",
+		},
+	}
+
+	b, err := json.Marshal(in)
+	assert.NoError(t, err, "marshal")
+
+	out := proxy.Output{
+		StatusCode: 200,
+		Headers: map[string]string{
+			"Set-Cookie":   "session=secret",
+			"Content-Type": "application/json",
+		},
+		Body: `[]`,
+	}
+
+	assert.NoError(t, r.Record(b, out), "record")
+	assert.Len(t, s.events, 1)
+
+	e := s.events[0]
+	assert.Equal(t, "production", e.Stage)
+	assert.Equal(t, "/pets", e.Input.Path)
+	assert.Equal(t, redacted, e.Input.Headers["Authorization"])
+	assert.Equal(t, "application/json", e.Input.Headers["Accept"])
+	assert.Equal(t, redacted, e.Input.QueryStringParameters["token"])
+	assert.Equal(t, "2", e.Input.QueryStringParameters["page"])
+	assert.Equal(t, redacted, e.Output.Headers["Set-Cookie"])
+	assert.Equal(t, "application/json", e.Output.Headers["Content-Type"])
+	assert.Equal(t, "session=secret", out.Headers["Set-Cookie"], "original output untouched")
+}
+
+func TestRecorder_sampled(t *testing.T) {
+	t.Run("all", func(t *testing.T) {
+		r := New(&config.Recording{Sample: 1}, "production", &memoryStore{})
+		for i := 0; i < 100; i++ {
+			assert.True(t, r.sampled())
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		r := New(&config.Recording{Sample: 0}, "production", &memoryStore{})
+		for i := 0; i < 100; i++ {
+			assert.False(t, r.sampled())
+		}
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up-record")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.json")
+	s := NewFileStore(path)
+
+	assert.NoError(t, s.Put(&Event{Stage: "staging", Input: proxy.Input{Path: "/a"}}))
+	assert.NoError(t, s.Put(&Event{Stage: "staging", Input: proxy.Input{Path: "/b"}}))
+
+	events, err := ReadFile(path)
+	assert.NoError(t, err, "read")
+	assert.Len(t, events, 2)
+	assert.Equal(t, "/a", events[0].Input.Path)
+	assert.Equal(t, "/b", events[1].Input.Path)
+}
+
+func TestRead(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		events, err := Read(strings.NewReader("{\"stage\":\"a\"}\n\n{\"stage\":\"b\"}\n"))
+		assert.NoError(t, err, "read")
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Read(strings.NewReader("{\"stage\":\"a\"}\n{\n"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		a := proxy.Output{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "text/plain", "Date": "Mon"},
+			Body:       "hello",
+		}
+
+		b := proxy.Output{
+			StatusCode:      200,
+			Headers:         map[string]string{"content-type": "text/plain", "Date": "Tue"},
+			Body:            "aGVsbG8=",
+			IsBase64Encoded: true,
+		}
+
+		assert.Empty(t, Diff(a, b))
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		a := proxy.Output{StatusCode: 200, Headers: map[string]string{"Set-Cookie": redacted}}
+		b := proxy.Output{StatusCode: 200, Headers: map[string]string{"Set-Cookie": "id=1"}}
+		assert.Empty(t, Diff(a, b))
+	})
+
+	t.Run("different", func(t *testing.T) {
+		a := proxy.Output{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "text/plain", "X-Foo": "bar"},
+			Body:       "hello",
+		}
+
+		b := proxy.Output{
+			StatusCode: 500,
+			Headers:    map[string]string{"Content-Type": "text/html", "X-Bar": "baz"},
+			Body:       "error",
+		}
+
+		assert.Equal(t, []string{
+			`status 200 → 500`,
+			`header Content-Type "text/plain" → "text/html"`,
+			`header X-Bar added "baz"`,
+			`header X-Foo removed "bar"`,
+			`body`,
+		}, Diff(a, b))
+	})
+}
@@ -0,0 +1,83 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// FileStore appends newline-delimited JSON events to a file.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore returns a new file store.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Put implementation.
+func (s *FileStore) Put(e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "opening")
+	}
+
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		f.Close()
+		return errors.Wrap(err, "encoding")
+	}
+
+	return f.Close()
+}
+
+// S3Store writes each event as an object in S3.
+type S3Store struct {
+	bucket string
+	prefix string
+	client *s3.S3
+}
+
+// NewS3Store returns a new S3 store.
+func NewS3Store(bucket, prefix string) *S3Store {
+	return &S3Store{
+		bucket: bucket,
+		prefix: prefix,
+		client: s3.New(session.New(aws.NewConfig())),
+	}
+}
+
+// Put implementation.
+func (s *S3Store) Put(e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "marshaling")
+	}
+
+	_, err = s.client.PutObject(&s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         aws.String(s.key(e)),
+		Body:        bytes.NewReader(append(b, '\n')),
+		ContentType: aws.String("application/json"),
+	})
+
+	return err
+}
+
+// key returns the object key for an event.
+func (s *S3Store) key(e *Event) string {
+	name := fmt.Sprintf("%d-%s.json", e.Time.UnixNano(), e.Input.RequestContext.RequestID)
+	return path.Join(s.prefix, e.Stage, e.Time.Format("2006/01/02"), name)
+}
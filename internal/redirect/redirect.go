@@ -3,11 +3,16 @@
 package redirect
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/fanyang01/radix"
 	"github.com/pkg/errors"
 )
 
@@ -16,14 +21,46 @@ var placeholders = regexp.MustCompile(`:(\w+)`)
 
 // Rule is a single redirect rule.
 type Rule struct {
-	Path     string `json:"path"`
+	// Path pattern, such as "/docs/*" or "/blog/:year/:slug".
+	Path string `json:"path"`
+
+	// Location of the redirect or rewrite, which may
+	// reference the path's placeholders and splat.
 	Location string `json:"location"`
-	Status   int    `json:"status"`
-	Force    bool   `json:"force"`
-	names    map[string]bool
-	dynamic  bool
-	sub      string
-	path     *regexp.Regexp
+
+	// Status of the redirect, or 200 for rewrites.
+	Status int `json:"status"`
+
+	// Force the rewrite even when the path exists.
+	Force bool `json:"force"`
+
+	// Host the request must be for, such as "www.example.com"
+	// or "*.example.com". Defaults to any host.
+	Host string `json:"host"`
+
+	// Query parameters the request must have. A value of "*" matches any
+	// value, while a placeholder such as ":id" matches any value and
+	// may be referenced in the location.
+	Query map[string]string `json:"query"`
+
+	// Headers the request must have. A value of "*" matches any value.
+	Headers map[string]string `json:"headers"`
+
+	// Cookie values the request must have. A value of "*" matches any value.
+	Cookie map[string]string `json:"cookie"`
+
+	// Language is a list of languages, one of which must be accepted
+	// by the request, such as "fr" matching "fr" and "fr-CA".
+	Language []string `json:"language"`
+
+	// QueryPassthrough appends the request's query string to the location,
+	// excluding parameters matched by the query conditions.
+	QueryPassthrough bool `json:"query_passthrough"`
+
+	names   map[string]bool
+	dynamic bool
+	sub     string
+	path    *regexp.Regexp
 }
 
 // URL returns the final destination after substitutions from path.
@@ -31,6 +68,100 @@ func (r Rule) URL(path string) string {
 	return r.path.ReplaceAllString(path, r.sub)
 }
 
+// Destination returns the final destination after substitutions
+// from the request's path and query string.
+func (r *Rule) Destination(req *http.Request) string {
+	values := make(map[string]string)
+
+	m := r.path.FindStringSubmatch(req.URL.Path)
+	for i, name := range r.path.SubexpNames() {
+		if name != "" && i < len(m) {
+			values[name] = m[i]
+		}
+	}
+
+	query := req.URL.Query()
+	for name, v := range r.Query {
+		if isPlaceholder(v) {
+			values[v[1:]] = query.Get(name)
+		}
+	}
+
+	s := placeholders.ReplaceAllStringFunc(r.Location, func(v string) string {
+		if value, ok := values[v[1:]]; ok {
+			return value
+		}
+		return v
+	})
+
+	if !r.QueryPassthrough {
+		return s
+	}
+
+	for name := range r.Query {
+		query.Del(name)
+	}
+
+	if len(query) == 0 {
+		return s
+	}
+
+	if strings.Contains(s, "?") {
+		return s + "&" + query.Encode()
+	}
+
+	return s + "?" + query.Encode()
+}
+
+// Match returns true if the request satisfies the rule's path and conditions.
+func (r *Rule) Match(req *http.Request) bool {
+	if !r.path.MatchString(req.URL.Path) {
+		return false
+	}
+
+	if r.Host != "" && !matchHost(r.Host, req.Host) {
+		return false
+	}
+
+	query := req.URL.Query()
+	for name, v := range r.Query {
+		if _, ok := query[name]; !ok {
+			return false
+		}
+
+		if !isPlaceholder(v) && !matchValue(v, query.Get(name)) {
+			return false
+		}
+	}
+
+	for name, v := range r.Headers {
+		if _, ok := req.Header[http.CanonicalHeaderKey(name)]; !ok {
+			return false
+		}
+
+		if !matchValue(v, req.Header.Get(name)) {
+			return false
+		}
+	}
+
+	for name, v := range r.Cookie {
+		c, err := req.Cookie(name)
+		if err != nil {
+			return false
+		}
+
+		if !matchValue(v, c.Value) {
+			return false
+		}
+	}
+
+	if len(r.Language) > 0 && !matchLanguage(r.Language, req.Header.Get("Accept-Language")) {
+		return false
+	}
+
+	return true
+}
+
 // IsDynamic returns true if a splat or placeholder is used.
 func (r *Rule) IsDynamic() bool {
 	return r.dynamic
@@ -44,44 +175,203 @@ func (r *Rule) IsRewrite() bool {
 // Compile the rule.
 func (r *Rule) Compile() {
 	r.path, r.names = compilePath(r.Path)
+
+	for _, v := range r.Query {
+		if isPlaceholder(v) {
+			r.names[v[1:]] = true
+		}
+	}
+
 	r.sub = compileSub(r.Path, r.Location, r.names)
 	r.dynamic = isDynamic(r.Path)
 }
 
-// Rules map of paths to redirects.
-type Rules map[string]Rule
+// Validate implementation.
+func (r *Rule) Validate() error {
+	if r.Path == "" {
+		return errors.New(".path is required")
+	}
 
-// Matcher for header lookup.
-type Matcher struct {
-	t *radix.PatternTrie
+	if r.Location == "" {
+		return errors.New(".location is required")
+	}
+
+	if !r.IsRewrite() && (r.Status < 300 || r.Status > 399) {
+		return errors.Errorf(".status %d is invalid, must be 200 or a 3xx redirect", r.Status)
+	}
+
+	for _, lang := range r.Language {
+		if strings.TrimSpace(lang) == "" {
+			return errors.New(".language must not contain empty values")
+		}
+	}
+
+	return nil
 }
 
-// Lookup returns fields for the given path.
-func (m *Matcher) Lookup(path string) *Rule {
-	v, ok := m.t.Lookup(path)
-	if !ok {
+// Rules is an ordered list of redirect rules, the first matching rule
+// is used. Rules may also be specified as a map of paths to rules, in
+// which case more specific paths take precedence.
+type Rules []*Rule
+
+// UnmarshalJSON implementation.
+func (r *Rules) UnmarshalJSON(b []byte) error {
+	var rules []*Rule
+
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		if err := json.Unmarshal(b, &rules); err != nil {
+			return err
+		}
+
+		*r = rules
 		return nil
 	}
 
-	r := v.(Rule)
-	return &r
+	var m map[string]*Rule
+
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	for path, rule := range m {
+		if rule == nil {
+			rule = &Rule{}
+		}
+		rule.Path = path
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return moreSpecific(rules[i].Path, rules[j].Path)
+	})
+
+	*r = rules
+	return nil
+}
+
+// Validate implementation.
+func (r Rules) Validate() error {
+	for i, rule := range r {
+		if err := rule.Validate(); err != nil {
+			return errors.Wrapf(err, "rule #%d %q", i+1, rule.Path)
+		}
+	}
+
+	return nil
 }
 
-// Compile the given rules to a trie.
+// Matcher for redirect lookup.
+type Matcher struct {
+	rules Rules
+}
+
+// Lookup returns the first rule matching the request.
+func (m *Matcher) Lookup(r *http.Request) *Rule {
+	for _, rule := range m.rules {
+		if rule.Match(r) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// Compile the given rules.
 func Compile(rules Rules) (*Matcher, error) {
-	t := radix.NewPatternTrie()
-	m := &Matcher{t}
+	m := &Matcher{}
 
-	for path, rule := range rules {
-		rule.Path = path
-		rule.Compile()
-		t.Add(compilePattern(path), rule)
-		t.Add(compilePattern(path)+"/", rule)
+	for _, rule := range rules {
+		r := *rule
+		r.Compile()
+		m.rules = append(m.rules, &r)
 	}
 
 	return m, nil
 }
 
+// moreSpecific returns true if pattern a takes precedence over b,
+// a literal character takes precedence over a splat or placeholder.
+func moreSpecific(a, b string) bool {
+	x, y := compilePattern(a), compilePattern(b)
+
+	for i := 0; i < len(x) && i < len(y); i++ {
+		switch {
+		case x[i] == y[i]:
+			continue
+		case x[i] == '*':
+			return false
+		case y[i] == '*':
+			return true
+		default:
+			return x < y
+		}
+	}
+
+	switch {
+	case len(x) < len(y):
+		return !strings.HasSuffix(x, "*")
+	case len(x) > len(y):
+		return strings.HasSuffix(y, "*")
+	default:
+		return a < b
+	}
+}
+
+// matchHost returns true if host matches the pattern,
+// which may start with a "*." wildcard.
+func matchHost(pattern, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		return len(host) > len(pattern)-1 && strings.HasSuffix(strings.ToLower(host), strings.ToLower(pattern[1:]))
+	}
+
+	return strings.EqualFold(pattern, host)
+}
+
+// matchValue returns true if the value matches
+// the pattern, where "*" matches any value.
+func matchValue(pattern, v string) bool {
+	return pattern == "*" || strings.EqualFold(pattern, v)
+}
+
+// matchLanguage returns true if one of the languages
+// is accepted by the Accept-Language header field.
+func matchLanguage(languages []string, header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		if tag == "" || !accepted(fields[1:]) {
+			continue
+		}
+
+		for _, lang := range languages {
+			lang = strings.ToLower(lang)
+			if tag == lang || strings.HasPrefix(tag, lang+"-") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// accepted returns false if the parameters contain a quality of zero.
+func accepted(params []string) bool {
+	for _, p := range params {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "q=") {
+			q, err := strconv.ParseFloat(p[2:], 64)
+			return err == nil && q > 0
+		}
+	}
+
+	return true
+}
+
 // compileSub returns a substitution string.
 func compileSub(path, s string, names map[string]bool) string {
 	// splat
@@ -137,6 +427,11 @@ func isDynamic(s string) bool {
 	return hasPlaceholder(s) || hasSplat(s)
 }
 
+// isPlaceholder returns true if s is a single placeholder such as ":id".
+func isPlaceholder(s string) bool {
+	return placeholders.FindString(s) == s && s != ""
+}
+
 // hasPlaceholder returns true for placeholders
 func hasPlaceholder(s string) bool {
 	return strings.ContainsRune(s, ':')
@@ -1,7 +1,10 @@
 package redirect
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/tj/assert"
@@ -68,17 +71,24 @@ func TestRule_URL(t *testing.T) {
 	})
 }
 
+func lookup(m *Matcher, path string) *Rule {
+	return m.Lookup(httptest.NewRequest("GET", path, nil))
+}
+
 func TestMatcher_Lookup(t *testing.T) {
 	rules := Rules{
-		"/docs/:product/guides/:guide": Rule{
+		{
+			Path:     "/docs/:product/guides/:guide",
 			Location: "/help/:product/:guide",
 			Status:   301,
 		},
-		"/blog": Rule{
+		{
+			Path:     "/blog",
 			Location: "https://blog.apex.sh",
 			Status:   302,
 		},
-		"/articles/*": Rule{
+		{
+			Path:     "/articles/*",
 			Location: "/guides/:splat",
 		},
 	}
@@ -87,36 +97,199 @@ func TestMatcher_Lookup(t *testing.T) {
 	assert.NoError(t, err, "compile")
 
 	t.Run("exact", func(t *testing.T) {
-		assert.NotNil(t, m.Lookup("/blog"))
+		assert.NotNil(t, lookup(m, "/blog"))
 	})
 
 	t.Run("exact trailing slash", func(t *testing.T) {
-		assert.NotNil(t, m.Lookup("/blog/"))
+		assert.NotNil(t, lookup(m, "/blog/"))
 	})
 
 	t.Run("placeholders", func(t *testing.T) {
-		assert.NotNil(t, m.Lookup("/docs/ping/guides/alerts"))
+		assert.NotNil(t, lookup(m, "/docs/ping/guides/alerts"))
 	})
 
-	// TODO: need to fork the trie to be less greedy
-	// t.Run("mismatch", func(t *testing.T) {
-	// 	r := m.Lookup("/docs/ping/another/guides/alerts")
-	// 	assert.NotNil(t, r)
-	// })
+	t.Run("mismatch", func(t *testing.T) {
+		assert.Nil(t, lookup(m, "/docs/ping/another/guides/alerts"))
+	})
 
 	t.Run("splat one segment", func(t *testing.T) {
-		assert.NotNil(t, m.Lookup("/articles/alerting"))
+		assert.NotNil(t, lookup(m, "/articles/alerting"))
 	})
 
 	t.Run("splat many segments", func(t *testing.T) {
-		assert.NotNil(t, m.Lookup("/articles/alerting/pagerduty"))
-		assert.NotNil(t, m.Lookup("/articles/alerting/pagerduty/"))
+		assert.NotNil(t, lookup(m, "/articles/alerting/pagerduty"))
+		assert.NotNil(t, lookup(m, "/articles/alerting/pagerduty/"))
+	})
+}
+
+func TestMatcher_Lookup_conditions(t *testing.T) {
+	rules := Rules{
+		{
+			Path:     "/*",
+			Host:     "www.example.com",
+			Location: "https://example.com/:splat",
+			Status:   301,
+		},
+		{
+			Path:     "/*",
+			Headers:  map[string]string{"X-Forwarded-Proto": "http"},
+			Location: "https://example.com/:splat",
+			Status:   301,
+		},
+		{
+			Path:     "/",
+			Cookie:   map[string]string{"beta": "*"},
+			Location: "/beta",
+		},
+		{
+			Path:     "/",
+			Language: []string{"fr"},
+			Location: "/fr",
+			Status:   302,
+		},
+		{
+			Path:     "/store",
+			Query:    map[string]string{"id": ":id", "view": "full"},
+			Location: "/products/:id",
+			Status:   301,
+		},
+		{
+			Path:             "/*",
+			Query:            map[string]string{"ref": "*"},
+			Location:         "/:splat",
+			Status:           301,
+			QueryPassthrough: true,
+		},
+	}
+
+	m, err := Compile(rules)
+	assert.NoError(t, err, "compile")
+
+	t.Run("host", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://www.example.com:8080/docs", nil)
+		rule := m.Lookup(r)
+		assert.NotNil(t, rule)
+		assert.Equal(t, "https://example.com/docs", rule.Destination(r))
+	})
+
+	t.Run("header", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/docs", nil)
+		assert.Nil(t, m.Lookup(r))
+
+		r.Header.Set("X-Forwarded-Proto", "http")
+		assert.Equal(t, "https://example.com/docs", m.Lookup(r).Destination(r))
 	})
+
+	t.Run("cookie", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(&http.Cookie{Name: "beta", Value: "1"})
+		assert.Equal(t, "/beta", m.Lookup(r).Destination(r))
+	})
+
+	t.Run("language", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Language", "de;q=0.9, fr-CA")
+		assert.Equal(t, "/fr", m.Lookup(r).Destination(r))
+
+		r.Header.Set("Accept-Language", "de, fr;q=0")
+		assert.Nil(t, m.Lookup(r))
+	})
+
+	t.Run("query placeholder", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/store?id=5&view=full", nil)
+		assert.Equal(t, "/products/5", m.Lookup(r).Destination(r))
+
+		r = httptest.NewRequest("GET", "/store?id=5", nil)
+		assert.Nil(t, m.Lookup(r))
+	})
+
+	t.Run("query passthrough", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/docs?ref=twitter&page=2", nil)
+		assert.Equal(t, "/docs?page=2", m.Lookup(r).Destination(r))
+
+		r = httptest.NewRequest("GET", "/docs?ref=twitter", nil)
+		assert.Equal(t, "/docs", m.Lookup(r).Destination(r))
+	})
+}
+
+func TestMatcher_Lookup_precedence(t *testing.T) {
+	t.Run("first match", func(t *testing.T) {
+		m, err := Compile(Rules{
+			{Path: "/*", Location: "/index.html"},
+			{Path: "/blog", Location: "/news", Status: 301},
+		})
+		assert.NoError(t, err, "compile")
+		assert.Equal(t, "/index.html", lookup(m, "/blog").Location)
+	})
+
+	t.Run("conditions before fallback", func(t *testing.T) {
+		m, err := Compile(Rules{
+			{Path: "/", Language: []string{"fr"}, Location: "/fr", Status: 302},
+			{Path: "/", Location: "/en", Status: 302},
+		})
+		assert.NoError(t, err, "compile")
+
+		r := httptest.NewRequest("GET", "/", nil)
+		assert.Equal(t, "/en", m.Lookup(r).Location)
+
+		r.Header.Set("Accept-Language", "fr")
+		assert.Equal(t, "/fr", m.Lookup(r).Location)
+	})
+
+	t.Run("trailing slash", func(t *testing.T) {
+		m, err := Compile(Rules{
+			{Path: "/*/", Location: "/:splat", Status: 301},
+		})
+		assert.NoError(t, err, "compile")
+		assert.Nil(t, lookup(m, "/"))
+		assert.Nil(t, lookup(m, "/docs"))
+		assert.Equal(t, "/docs/guides", lookup(m, "/docs/guides/").URL("/docs/guides/"))
+	})
+}
+
+func TestRules_UnmarshalJSON(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		var rules Rules
+		err := json.Unmarshal([]byte(`[
+			{ "path": "/*", "location": "/" },
+			{ "path": "/blog", "location": "/news", "status": 301 }
+		]`), &rules)
+		assert.NoError(t, err, "unmarshal")
+		assert.Equal(t, "/*", rules[0].Path)
+		assert.Equal(t, "/blog", rules[1].Path)
+	})
+
+	t.Run("map by specificity", func(t *testing.T) {
+		var rules Rules
+		err := json.Unmarshal([]byte(`{
+			"/*": { "location": "/" },
+			"/app/*": { "location": "/" },
+			"/app/*/edit": { "location": "/edit" },
+			"/app/:id": { "location": "/" },
+			"/app/login": { "location": "/login", "status": 301 },
+			"/blog": { "location": "/news", "status": 301 }
+		}`), &rules)
+		assert.NoError(t, err, "unmarshal")
+
+		var paths []string
+		for _, r := range rules {
+			paths = append(paths, r.Path)
+		}
+
+		assert.Equal(t, []string{"/app/login", "/app/*/edit", "/app/*", "/app/:id", "/blog", "/*"}, paths)
+	})
+}
+
+func TestRules_Validate(t *testing.T) {
+	assert.NoError(t, Rules{{Path: "/", Location: "/app"}}.Validate())
+	assert.EqualError(t, Rules{{Path: "/", Location: "/app", Status: 404}}.Validate(), `rule #1 "/": .status 404 is invalid, must be 200 or a 3xx redirect`)
+	assert.EqualError(t, Rules{{Path: "/"}}.Validate(), `rule #1 "/": .location is required`)
 }
 
 func BenchmarkMatcher_Lookup(b *testing.B) {
 	rules := Rules{
-		"/docs/:product/guides/:guide": Rule{
+		{
+			Path:     "/docs/:product/guides/:guide",
 			Location: "/help/:product/:guide",
 			Status:   301,
 		},
@@ -129,13 +302,13 @@ func BenchmarkMatcher_Lookup(b *testing.B) {
 
 	b.Run("match", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			fmt.Printf("%#v\n", m.Lookup("/docs/ping/guides/alerts"))
+			fmt.Printf("%#v\n", lookup(m, "/docs/ping/guides/alerts"))
 		}
 	})
 
 	b.Run("mismatch", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			m.Lookup("/some/other/page")
+			lookup(m, "/some/other/page")
 		}
 	})
 }
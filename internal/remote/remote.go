@@ -0,0 +1,156 @@
+// Package remote provides signed one-off command
+// execution within the deployed function environment.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/apex/go-apex"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs"
+)
+
+// log context.
+var ctx = logs.Plugin("remote")
+
+// SecretEnv is the environment variable holding the signing secret.
+const SecretEnv = "UP_RUN_SECRET"
+
+// maxSkew is the maximum age of a command.
+const maxSkew = 5 * time.Minute
+
+// errInvalidSignature is returned when the signature does not match.
+var errInvalidSignature = errors.New("invalid signature")
+
+// Command is a signed command.
+type Command struct {
+	Command   string `json:"command"`
+	Time      int64  `json:"time"`
+	Signature string `json:"signature"`
+}
+
+// Event is the invocation event wrapping a command.
+type Event struct {
+	Command *Command `json:"up_command"`
+}
+
+// Result is the result of a command.
+type Result struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// NewEvent returns a new event for command, signed with secret.
+func NewEvent(secret, command string) *Event {
+	t := time.Now().Unix()
+	return &Event{
+		Command: &Command{
+			Command:   command,
+			Time:      t,
+			Signature: sign(secret, command, t),
+		},
+	}
+}
+
+// Verify the command signature and age.
+func (c *Command) Verify(secret string, now time.Time) error {
+	if secret == "" {
+		return errors.New("secret is not configured")
+	}
+
+	expected := sign(secret, c.Command, c.Time)
+	if !hmac.Equal([]byte(expected), []byte(c.Signature)) {
+		return errInvalidSignature
+	}
+
+	d := now.Sub(time.Unix(c.Time, 0))
+	if d > maxSkew || d < -maxSkew {
+		return errors.New("command has expired")
+	}
+
+	return nil
+}
+
+// Run the command with sh -c in the working directory
+// and environment of the process, with timeout.
+func Run(command string, timeout time.Duration) *Result {
+	c, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(c, "sh", "-c", command)
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	res := &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode(err),
+	}
+
+	if c.Err() == context.DeadlineExceeded {
+		res.Stderr += "command timed out after " + timeout.String() + "\n"
+	}
+
+	return res
+}
+
+// NewHandler returns a handler running signed command events,
+// otherwise delegating to h.
+func NewHandler(secret string, timeout time.Duration, h apex.Handler) apex.Handler {
+	return apex.HandlerFunc(func(event json.RawMessage, c *apex.Context) (interface{}, error) {
+		var e Event
+
+		if err := json.Unmarshal(event, &e); err != nil || e.Command == nil {
+			return h.Handle(event, c)
+		}
+
+		if err := e.Command.Verify(secret, time.Now()); err != nil {
+			ctx.WithError(err).Warn("rejecting command")
+			return nil, errors.Wrap(err, "verifying command")
+		}
+
+		ctx.WithField("command", e.Command.Command).Info("running command")
+		return Run(e.Command.Command, timeout), nil
+	})
+}
+
+// sign returns the signature for command at time t.
+func sign(secret, command string, t int64) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(strconv.FormatInt(t, 10)))
+	m.Write([]byte{'\n'})
+	m.Write([]byte(command))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// exitCode returns the exit code for err.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if e, ok := err.(*exec.ExitError); ok {
+		if s, ok := e.Sys().(syscall.WaitStatus); ok && s.Exited() {
+			return s.ExitStatus()
+		}
+	}
+
+	return 1
+}
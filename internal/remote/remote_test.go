@@ -0,0 +1,89 @@
+package remote
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apex/go-apex"
+	"github.com/tj/assert"
+)
+
+func TestCommand_Verify(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		e := NewEvent("secret", "echo hello")
+		assert.NoError(t, e.Command.Verify("secret", time.Now()))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		e := NewEvent("secret", "echo hello")
+		assert.EqualError(t, e.Command.Verify("other", time.Now()), `invalid signature`)
+	})
+
+	t.Run("tampered command", func(t *testing.T) {
+		e := NewEvent("secret", "echo hello")
+		e.Command.Command = "rm -fr /"
+		assert.EqualError(t, e.Command.Verify("secret", time.Now()), `invalid signature`)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		e := NewEvent("secret", "echo hello")
+		assert.EqualError(t, e.Command.Verify("secret", time.Now().Add(time.Hour)), `command has expired`)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		e := NewEvent("", "echo hello")
+		assert.EqualError(t, e.Command.Verify("", time.Now()), `secret is not configured`)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		res := Run("echo hello && echo world >&2", time.Second)
+		assert.Equal(t, "hello\n", res.Stdout)
+		assert.Equal(t, "world\n", res.Stderr)
+		assert.Equal(t, 0, res.ExitCode)
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		res := Run("exit 3", time.Second)
+		assert.Equal(t, 3, res.ExitCode)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		res := Run("sleep 5", 50*time.Millisecond)
+		assert.NotEqual(t, 0, res.ExitCode)
+		assert.Contains(t, res.Stderr, "timed out")
+	})
+}
+
+func TestNewHandler(t *testing.T) {
+	next := apex.HandlerFunc(func(event json.RawMessage, c *apex.Context) (interface{}, error) {
+		return "next", nil
+	})
+
+	h := NewHandler("secret", time.Second, next)
+
+	t.Run("command", func(t *testing.T) {
+		b, err := json.Marshal(NewEvent("secret", "echo hello"))
+		assert.NoError(t, err, "marshal")
+
+		v, err := h.Handle(b, &apex.Context{})
+		assert.NoError(t, err, "handle")
+		assert.Equal(t, "hello\n", v.(*Result).Stdout)
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		b, err := json.Marshal(NewEvent("other", "echo hello"))
+		assert.NoError(t, err, "marshal")
+
+		_, err = h.Handle(b, &apex.Context{})
+		assert.EqualError(t, err, `verifying command: invalid signature`)
+	})
+
+	t.Run("other events", func(t *testing.T) {
+		v, err := h.Handle(json.RawMessage(`{ "httpMethod": "GET", "path": "/" }`), &apex.Context{})
+		assert.NoError(t, err, "handle")
+		assert.Equal(t, "next", v)
+	})
+}
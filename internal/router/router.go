@@ -0,0 +1,162 @@
+// Package router provides routing of requests to the function
+// alias of a specific commit by header field, cookie or query
+// string parameter, falling back to the stage when unknown.
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/apex/go-apex"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/proxy"
+	"github.com/apex/up/internal/util"
+)
+
+// log context.
+var ctx = logs.Plugin("router")
+
+// Names used to request a version.
+const (
+	Header = "X-Up-Version"
+	Cookie = "up-version"
+	Query  = "up-version"
+)
+
+// routed is the header field set on routed events,
+// preventing them from being routed again.
+const routed = "X-Up-Routed"
+
+// Invoker is the interface used to invoke a function alias.
+type Invoker interface {
+	Invoke(*lambda.InvokeInput) (*lambda.InvokeOutput, error)
+}
+
+// Router routes events to commit aliases.
+type Router struct {
+	name    string
+	commit  string
+	client  Invoker
+	handler apex.Handler
+}
+
+// New router for function name, currently running commit, falling back to h.
+func New(name, commit string, client Invoker, h apex.Handler) *Router {
+	return &Router{
+		name:    name,
+		commit:  commit,
+		client:  client,
+		handler: h,
+	}
+}
+
+// Handle implementation.
+func (r *Router) Handle(event json.RawMessage, c *apex.Context) (interface{}, error) {
+	var in proxy.Input
+
+	if err := json.Unmarshal(event, &in); err != nil {
+		return r.handler.Handle(event, c)
+	}
+
+	commit, sticky := Version(&in)
+	if commit == "" || commit == r.commit || header(in.Headers, routed) != "" {
+		return r.handler.Handle(event, c)
+	}
+
+	out, err := r.invoke(commit, &in)
+
+	if util.IsNotFound(err) {
+		ctx.WithField("commit", commit).Debug("unknown commit, falling back to stage")
+		return r.handler.Handle(event, c)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "invoking commit %q", commit)
+	}
+
+	if out.Headers == nil {
+		out.Headers = make(map[string]string)
+	}
+
+	out.Headers[Header] = commit
+
+	if sticky {
+		cookie := &http.Cookie{Name: Cookie, Value: commit, Path: "/"}
+		out.Headers["Set-Cookie"] = cookie.String()
+	}
+
+	return *out, nil
+}
+
+// invoke the commit alias with the event.
+func (r *Router) invoke(commit string, in *proxy.Input) (*proxy.Output, error) {
+	if in.Headers == nil {
+		in.Headers = make(map[string]string)
+	}
+
+	in.Headers[routed] = commit
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling")
+	}
+
+	res, err := r.client.Invoke(&lambda.InvokeInput{
+		FunctionName: &r.name,
+		Qualifier:    aws.String(util.EncodeAlias(commit)),
+		Payload:      b,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.FunctionError != nil {
+		return nil, errors.Errorf("function error: %s", res.Payload)
+	}
+
+	var out proxy.Output
+	if err := json.Unmarshal(res.Payload, &out); err != nil {
+		return nil, errors.Wrap(err, "parsing response")
+	}
+
+	return &out, nil
+}
+
+// Version returns the commit requested by the input, and
+// true when it was requested via query string and should
+// be persisted with a cookie.
+func Version(in *proxy.Input) (commit string, sticky bool) {
+	if v := header(in.Headers, Header); v != "" {
+		return v, false
+	}
+
+	if v := in.QueryStringParameters[Query]; v != "" {
+		return v, true
+	}
+
+	req := &http.Request{Header: http.Header{}}
+	req.Header.Set("Cookie", header(in.Headers, "Cookie"))
+
+	if c, err := req.Cookie(Cookie); err == nil {
+		return c.Value, false
+	}
+
+	return "", false
+}
+
+// header returns the value of a header field, case-insensitive.
+func header(m map[string]string, name string) string {
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+
+	return ""
+}
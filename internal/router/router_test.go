@@ -0,0 +1,121 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/apex/go-apex"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/tj/assert"
+
+	"github.com/apex/up/internal/proxy"
+)
+
+type invoker struct {
+	aliases map[string]string
+	calls   []string
+}
+
+func (i *invoker) Invoke(in *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	i.calls = append(i.calls, *in.Qualifier)
+
+	body, ok := i.aliases[*in.Qualifier]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException: Function not found")
+	}
+
+	b, _ := json.Marshal(proxy.Output{StatusCode: 200, Body: body})
+	return &lambda.InvokeOutput{Payload: b}, nil
+}
+
+var stage = apex.HandlerFunc(func(event json.RawMessage, c *apex.Context) (interface{}, error) {
+	return proxy.Output{StatusCode: 200, Body: "stage"}, nil
+})
+
+func handle(t *testing.T, h apex.Handler, in proxy.Input) proxy.Output {
+	b, err := json.Marshal(in)
+	assert.NoError(t, err, "marshal")
+
+	v, err := h.Handle(b, &apex.Context{})
+	assert.NoError(t, err, "handle")
+
+	return v.(proxy.Output)
+}
+
+func TestRouter(t *testing.T) {
+	client := &invoker{aliases: map[string]string{"commit-abc": "abc"}}
+	h := New("app", "def", client, stage)
+
+	t.Run("no version", func(t *testing.T) {
+		out := handle(t, h, proxy.Input{HTTPMethod: "GET", Path: "/"})
+		assert.Equal(t, "stage", out.Body)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		out := handle(t, h, proxy.Input{
+			HTTPMethod: "GET",
+			Path:       "/",
+			Headers:    map[string]string{"x-up-version": "abc"},
+		})
+
+		assert.Equal(t, "abc", out.Body)
+		assert.Equal(t, "abc", out.Headers["X-Up-Version"])
+		assert.Empty(t, out.Headers["Set-Cookie"])
+	})
+
+	t.Run("cookie", func(t *testing.T) {
+		out := handle(t, h, proxy.Input{
+			HTTPMethod: "GET",
+			Path:       "/",
+			Headers:    map[string]string{"Cookie": "foo=bar; up-version=abc"},
+		})
+
+		assert.Equal(t, "abc", out.Body)
+	})
+
+	t.Run("query string", func(t *testing.T) {
+		out := handle(t, h, proxy.Input{
+			HTTPMethod:            "GET",
+			Path:                  "/",
+			QueryStringParameters: map[string]string{"up-version": "abc"},
+		})
+
+		assert.Equal(t, "abc", out.Body)
+		assert.Equal(t, "up-version=abc; Path=/", out.Headers["Set-Cookie"])
+	})
+
+	t.Run("current commit", func(t *testing.T) {
+		client.calls = nil
+		out := handle(t, h, proxy.Input{
+			HTTPMethod: "GET",
+			Path:       "/",
+			Headers:    map[string]string{"X-Up-Version": "def"},
+		})
+
+		assert.Equal(t, "stage", out.Body)
+		assert.Empty(t, client.calls)
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		out := handle(t, h, proxy.Input{
+			HTTPMethod: "GET",
+			Path:       "/",
+			Headers:    map[string]string{"X-Up-Version": "nope"},
+		})
+
+		assert.Equal(t, "stage", out.Body)
+	})
+
+	t.Run("routed", func(t *testing.T) {
+		client.calls = nil
+		out := handle(t, h, proxy.Input{
+			HTTPMethod: "GET",
+			Path:       "/",
+			Headers:    map[string]string{"X-Up-Version": "abc", "X-Up-Routed": "abc"},
+		})
+
+		assert.Equal(t, "stage", out.Body)
+		assert.Empty(t, client.calls)
+	})
+}
@@ -30,7 +30,8 @@ import (
 
 // Fields retained when clearing.
 var keepFields = map[string]bool{
-	"X-Powered-By": true,
+	"X-Powered-By":     true,
+	"Www-Authenticate": true,
 }
 
 // ClearHeader removes all header fields.
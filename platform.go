@@ -96,6 +96,34 @@ type Zipper interface {
 	Zip() io.Reader
 }
 
+// Invoker is the interface used by platforms which
+// support invoking a deployed stage directly.
+type Invoker interface {
+	Invoke(region, stage string, payload []byte) (*Invocation, error)
+}
+
+// Invocation is the result of a direct invocation.
+type Invocation struct {
+	// Payload is the response payload.
+	Payload []byte
+
+	// Logs is the tail of the invocation logs.
+	Logs string
+}
+
+// Runner is the interface used by platforms which
+// support running commands in the deployed environment.
+type Runner interface {
+	Run(region, stage, command string) (*RunResult, error)
+}
+
+// RunResult is the result of a remote command.
+type RunResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
 // Domain is a domain name and its availability.
 type Domain struct {
 	Name      string
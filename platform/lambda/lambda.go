@@ -3,6 +3,8 @@ package lambda
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -30,6 +32,7 @@ import (
 	"github.com/apex/up"
 	"github.com/apex/up/config"
 	"github.com/apex/up/internal/proxy/bin"
+	"github.com/apex/up/internal/remote"
 	"github.com/apex/up/internal/shim"
 	"github.com/apex/up/internal/util"
 	"github.com/apex/up/internal/zip"
@@ -81,7 +84,8 @@ var functionPolicy = `{
 				"logs:CreateLogGroup",
 				"logs:CreateLogStream",
 				"logs:PutLogEvents",
-				"ssm:GetParametersByPath"
+				"ssm:GetParametersByPath",
+				"lambda:InvokeFunction"
 			]
 		}
 	]
@@ -224,6 +228,83 @@ func (p *Platform) URL(region, stage string) (string, error) {
 	return id, nil
 }
 
+// Invoke implementation.
+func (p *Platform) Invoke(region, stage string, payload []byte) (*up.Invocation, error) {
+	c := lambda.New(session.New(aws.NewConfig().WithRegion(region)))
+
+	res, err := c.Invoke(&lambda.InvokeInput{
+		FunctionName: &p.config.Name,
+		Qualifier:    &stage,
+		Payload:      payload,
+		LogType:      aws.String("Tail"),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "invoking")
+	}
+
+	var logs []byte
+	if res.LogResult != nil {
+		logs, err = base64.StdEncoding.DecodeString(*res.LogResult)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding logs")
+		}
+	}
+
+	if res.FunctionError != nil {
+		return nil, errors.Errorf("function error: %s", res.Payload)
+	}
+
+	return &up.Invocation{
+		Payload: res.Payload,
+		Logs:    string(logs),
+	}, nil
+}
+
+// Run implementation.
+func (p *Platform) Run(region, stage, command string) (*up.RunResult, error) {
+	c := lambda.New(session.New(aws.NewConfig().WithRegion(region)))
+
+	fn, err := c.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{
+		FunctionName: &p.config.Name,
+		Qualifier:    &stage,
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching function config")
+	}
+
+	var secret string
+	if fn.Environment != nil {
+		secret = aws.StringValue(fn.Environment.Variables[remote.SecretEnv])
+	}
+
+	if secret == "" {
+		return nil, errors.New("stage does not support remote commands, try re-deploying")
+	}
+
+	b, err := json.Marshal(remote.NewEvent(secret, command))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling")
+	}
+
+	res, err := p.Invoke(region, stage, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var v remote.Result
+	if err := json.Unmarshal(res.Payload, &v); err != nil {
+		return nil, errors.Wrap(err, "parsing result")
+	}
+
+	return &up.RunResult{
+		Stdout:   v.Stdout,
+		Stderr:   v.Stderr,
+		ExitCode: v.ExitCode,
+	}, nil
+}
+
 // CreateStack implementation.
 func (p *Platform) CreateStack(region, version string) error {
 	versions := make(resources.Versions)
@@ -618,11 +699,6 @@ func (p *Platform) updateFunction(c *lambda.Lambda, a *apigateway.APIGateway, up
 		return "", errors.Wrap(err, "updating function code")
 	}
 
-	// create stage alias
-	if err := p.alias(c, d.Stage, *res.Version); err != nil {
-		return "", errors.Wrapf(err, "creating function stage %q alias", d.Stage)
-	}
-
 	// create git alias
 	if d.Commit != "" {
 		if err := p.alias(c, util.EncodeAlias(d.Commit), *res.Version); err != nil {
@@ -630,6 +706,26 @@ func (p *Platform) updateFunction(c *lambda.Lambda, a *apigateway.APIGateway, up
 		}
 	}
 
+	// previous stage version
+	prev, err := p.getAliasVersion(c, d.Stage)
+	if err != nil && !util.IsNotFound(err) {
+		return "", errors.Wrapf(err, "fetching function stage %q alias", d.Stage)
+	}
+
+	// shift traffic to the stage alias
+	if prev != "" && prev != *res.Version && len(p.config.Deploy.Steps()) > 0 {
+		if err := p.shift(c, region, d.Stage, prev, *res.Version); err != nil {
+			return "", errors.Wrapf(err, "shifting function stage %q traffic", d.Stage)
+		}
+
+		return *res.Version, nil
+	}
+
+	// create stage alias
+	if err := p.alias(c, d.Stage, *res.Version); err != nil {
+		return "", errors.Wrapf(err, "creating function stage %q alias", d.Stage)
+	}
+
 	return *res.Version, nil
 }
 
@@ -641,6 +737,9 @@ func (p *Platform) alias(c *lambda.Lambda, alias, version string) error {
 		FunctionVersion: &version,
 		Name:            &alias,
 		Description:     aws.String(util.ManagedByUp("")),
+		RoutingConfig: &lambda.AliasRoutingConfiguration{
+			AdditionalVersionWeights: map[string]*float64{},
+		},
 	})
 
 	if util.IsNotFound(err) {
@@ -871,6 +970,7 @@ func toEnv(env config.Environment, d up.Deploy) *lambda.Environment {
 	m["UP_STAGE"] = &d.Stage
 	m["UP_COMMIT"] = &d.Commit
 	m["UP_AUTHOR"] = &d.Author
+	m[remote.SecretEnv] = aws.String(uniuri.NewLen(32))
 	return &lambda.Environment{
 		Variables: m,
 	}
@@ -62,37 +62,11 @@ func (p *Platform) ShowMetrics(region, stage string, start time.Time) error {
 	s := session.New(aws.NewConfig().WithRegion(region))
 	c := cloudwatch.New(s)
 	var g errgroup.Group
-	name := p.config.Name
-
-	d := time.Now().UTC().Sub(start)
 
 	for _, s := range stats {
 		s := s
 		g.Go(func() error {
-			m := metrics.New().
-				Namespace(s.Namespace).
-				TimeRange(time.Now().Add(-d), time.Now()).
-				Period(int(d.Seconds() * 2)).
-				Stat(s.Stat).
-				Metric(s.Metric)
-
-			switch s.Namespace {
-			case "AWS/ApiGateway":
-				m = m.Dimension("ApiName", name).Dimension("Stage", stage)
-			case "AWS/Lambda":
-				m = m.Dimension("FunctionName", name).Dimension("Resource", name+":"+stage)
-			}
-
-			res, err := c.GetMetricStatistics(m.Params())
-			if err != nil {
-				return err
-			}
-
-			if len(res.Datapoints) > 0 {
-				s.point = res.Datapoints[0]
-			}
-
-			return nil
+			return p.fetchStat(c, s, stage, start)
 		})
 	}
 
@@ -110,3 +84,37 @@ func (p *Platform) ShowMetrics(region, stage string, start time.Time) error {
 
 	return nil
 }
+
+// fetchStat fetches the value of s for the stage since start.
+func (p *Platform) fetchStat(c *cloudwatch.CloudWatch, s *stat, stage string, start time.Time) error {
+	name := p.config.Name
+	d := time.Now().UTC().Sub(start)
+
+	// periods must be a multiple of 60
+	period := (int(d.Seconds()*2)/60 + 1) * 60
+
+	m := metrics.New().
+		Namespace(s.Namespace).
+		TimeRange(time.Now().Add(-d), time.Now()).
+		Period(period).
+		Stat(s.Stat).
+		Metric(s.Metric)
+
+	switch s.Namespace {
+	case "AWS/ApiGateway":
+		m = m.Dimension("ApiName", name).Dimension("Stage", stage)
+	case "AWS/Lambda":
+		m = m.Dimension("FunctionName", name).Dimension("Resource", name+":"+stage)
+	}
+
+	res, err := c.GetMetricStatistics(m.Params())
+	if err != nil {
+		return err
+	}
+
+	if len(res.Datapoints) > 0 {
+		s.point = res.Datapoints[0]
+	}
+
+	return nil
+}
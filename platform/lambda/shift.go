@@ -0,0 +1,129 @@
+package lambda
+
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/platform/event"
+)
+
+// checkInterval is the interval between metrics checks while shifting traffic.
+var checkInterval = 30 * time.Second
+
+// shift gradually routes traffic for the stage alias from
+// the previous version to version, rolling back when
+// the rollback thresholds are crossed.
+func (p *Platform) shift(c *lambda.Lambda, region, stage, prev, version string) error {
+	cw := cloudwatch.New(session.New(aws.NewConfig().WithRegion(region)))
+	start := time.Now()
+
+	fields := event.Fields{
+		"region":   region,
+		"stage":    stage,
+		"version":  version,
+		"previous": prev,
+	}
+
+	for _, step := range p.config.Deploy.Steps() {
+		fields["percent"] = int(step.Weight * 100)
+		p.events.Emit("platform.deploy.shift", fields)
+
+		if err := p.route(c, stage, prev, version, step.Weight); err != nil {
+			return errors.Wrap(err, "routing traffic")
+		}
+
+		if err := p.watch(cw, stage, start, step.Wait); err != nil {
+			fields["reason"] = err.Error()
+			p.events.Emit("platform.deploy.rollback", fields)
+
+			if err := p.alias(c, stage, prev); err != nil {
+				return errors.Wrap(err, "rolling back")
+			}
+
+			return errors.Wrapf(err, "rolled back to version %s", prev)
+		}
+	}
+
+	fields["percent"] = 100
+	p.events.Emit("platform.deploy.shift", fields)
+
+	return p.alias(c, stage, version)
+}
+
+// route weight of the stage alias traffic to version,
+// and the remaining traffic to the previous version.
+func (p *Platform) route(c *lambda.Lambda, stage, prev, version string, weight float64) error {
+	log.Debugf("alias %s routing %.0f%% to %s", stage, weight*100, version)
+	_, err := c.UpdateAlias(&lambda.UpdateAliasInput{
+		FunctionName:    &p.config.Name,
+		FunctionVersion: &prev,
+		Name:            &stage,
+		Description:     aws.String(util.ManagedByUp("")),
+		RoutingConfig: &lambda.AliasRoutingConfiguration{
+			AdditionalVersionWeights: map[string]*float64{
+				version: aws.Float64(weight),
+			},
+		},
+	})
+
+	return err
+}
+
+// watch the stage metrics since start for duration d,
+// returning an error if the thresholds are crossed.
+func (p *Platform) watch(c *cloudwatch.CloudWatch, stage string, start time.Time, d time.Duration) error {
+	done := time.After(d)
+	tick := time.NewTicker(checkInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := p.check(c, stage, start); err != nil {
+				return err
+			}
+		case <-done:
+			return p.check(c, stage, start)
+		}
+	}
+}
+
+// check the stage metrics since start against the rollback thresholds.
+func (p *Platform) check(c *cloudwatch.CloudWatch, stage string, start time.Time) error {
+	r := p.config.Deploy.Rollback
+
+	checks := []struct {
+		stat      *stat
+		threshold int
+	}{
+		{&stat{"AWS/Lambda", "Errors", "Errors", "Sum", nil}, r.Errors},
+		{&stat{"AWS/ApiGateway", "Errors 5xx", "5XXError", "Sum", nil}, r.Errors5xx},
+		{&stat{"AWS/ApiGateway", "Duration avg", "Latency", "Average", nil}, r.Latency},
+	}
+
+	for _, check := range checks {
+		s := check.stat
+
+		if check.threshold == 0 {
+			continue
+		}
+
+		if err := p.fetchStat(c, s, stage, start); err != nil {
+			log.WithError(err).Warnf("fetching %s metric", s.Metric)
+			continue
+		}
+
+		if v := s.Value(); v >= check.threshold {
+			return errors.Errorf("%s of %d crossed the threshold of %d", s.Name, v, check.threshold)
+		}
+	}
+
+	return nil
+}
@@ -55,6 +55,10 @@ func (r *reporter) Start() {
 		case "platform.build.zip":
 			s := fmt.Sprintf("%s files, %s", humanize.Comma(e.Int64("files")), humanize.Bytes(uint64(e.Int("size_compressed"))))
 			r.complete("build", s, e.Duration("duration"))
+		case "platform.deploy.shift":
+			r.log("deploy", fmt.Sprintf("%d%% of traffic to version %s", e.Int("percent"), e.String("version")))
+		case "platform.deploy.rollback":
+			r.log("rollback", fmt.Sprintf("version %s, %s", e.String("previous"), e.String("reason")))
 		case "platform.deploy.complete":
 			s := "complete"
 			if v := e.String("version"); v != "" {
@@ -137,6 +137,11 @@ func (r *reporter) Start() {
 					s = "version " + v
 				}
 				r.complete("deploy", s, e.Duration("duration"))
+			case "platform.deploy.shift":
+				r.pending("deploy", fmt.Sprintf("%d%% of traffic to version %s", e.Int("percent"), e.String("version")))
+			case "platform.deploy.rollback":
+				r.clear()
+				r.error("rollback", fmt.Sprintf("version %s, %s", e.String("previous"), e.String("reason")))
 			case "platform.function.create":
 				r.inlineProgress = true
 			case "stack.create":
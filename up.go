@@ -156,6 +156,37 @@ func (p *Project) Zip() (io.Reader, error) {
 	return z.Zip(), nil
 }
 
+// Invoke the given stage directly with payload.
+func (p *Project) Invoke(region, stage string, payload []byte) (*Invocation, error) {
+	defer p.events.Time("platform.invoke", event.Fields{
+		"region": region,
+		"stage":  stage,
+	})()
+
+	v, ok := p.Platform.(Invoker)
+	if !ok {
+		return nil, errors.Errorf("platform does not support invocation")
+	}
+
+	return v.Invoke(region, stage, payload)
+}
+
+// RunRemote runs command in the given stage's environment.
+func (p *Project) RunRemote(region, stage, command string) (*RunResult, error) {
+	defer p.events.Time("platform.run", event.Fields{
+		"region":  region,
+		"stage":   stage,
+		"command": command,
+	})()
+
+	r, ok := p.Platform.(Runner)
+	if !ok {
+		return nil, errors.Errorf("platform does not support running remote commands")
+	}
+
+	return r.Run(region, stage, command)
+}
+
 // Init initializes the runtime such as remote environment variables.
 func (p *Project) Init(stage string) error {
 	r, ok := p.Platform.(Runtime)